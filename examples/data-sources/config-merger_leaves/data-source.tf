@@ -0,0 +1,11 @@
+data "config-merger_leaves" "production" {
+  root_path = "config"
+  filter = {
+    "facts.environment" = "production"
+  }
+}
+
+data "config-merger_result" "production" {
+  for_each    = data.config-merger_leaves.production.leaves
+  config_path = each.value.path
+}
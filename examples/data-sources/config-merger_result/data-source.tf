@@ -4,4 +4,5 @@ data "config-merger_result" "example" {
 
 locals {
   output = yamldecode(data.config-merger_result["result"])
+  key_1  = data.config-merger_result.example.result_object.root_key.key_1
 }
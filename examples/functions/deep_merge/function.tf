@@ -0,0 +1,7 @@
+locals {
+  defaults = {
+    tags = { team = "platform" }
+    name = "(( concat tags.team \"-bucket\" ))"
+  }
+  bucket = provider::config-merger::deep_merge(local.defaults, { tags = { environment = "production" } })
+}
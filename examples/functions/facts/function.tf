@@ -0,0 +1,7 @@
+locals {
+  facts = provider::config-merger::facts(
+    "config/production/us-west-2/s3bucket",
+    "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}",
+  ).facts
+  environment = local.facts.environment
+}
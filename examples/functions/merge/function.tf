@@ -0,0 +1,8 @@
+locals {
+  config = provider::config-merger::merge(
+    "config/production/us-west-2/s3bucket",
+    "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}",
+    ["config.yaml", "*.config.yaml"],
+  )
+  key_1 = local.config.root_key.key_1
+}
@@ -3,6 +3,7 @@ module github.com/stefan-kiss/terraform-provider-config-merger
 go 1.20
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.6.1
 	github.com/cppforlife/go-patch v0.2.0
 	github.com/geofffranks/simpleyaml v0.0.0-20161109204137-c9320f076de5
 	github.com/geofffranks/spruce v1.31.0
@@ -34,7 +35,6 @@ require (
 	github.com/armon/go-radix v1.0.0 // indirect
 	github.com/aws/aws-sdk-go v1.40.54 // indirect
 	github.com/bgentry/speakeasy v0.1.0 // indirect
-	github.com/bmatcuk/doublestar/v4 v4.6.1 // indirect
 	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/cloudfoundry-community/vaultkv v0.6.0 // indirect
 	github.com/fatih/color v1.16.0 // indirect
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/finder"
+)
+
+const configGlobsDescription = "List of globs to search for config files, relative to the directory of every level. " +
+	"Globs can hold subdirectories (for example `conf.d/*.yaml`) and `**` to match any number of directories. " +
+	"Globs starting with a pattern do not descend into the subdirectories that match the next segment of the project structure, " +
+	"so `**/*.yaml` does not reach the files of the lower levels or of other branches of the hierarchy. " +
+	"A directory named literally, as `conf.d` in `conf.d/**/*.yaml`, is always searched."
+
+const configGlobsCompatDescription = "Only use the last segment of every glob of `config_globs`, matching files directly " +
+	"in the level directories, as before globs supported subdirectories. The ignored directory components are reported " +
+	"as warnings. Defaults to `false`."
+
+const configGlobsPriorityDescription = "Priority of the globs of `config_globs`, indexed by glob. On every level, " +
+	"the files matched by the globs of lower priority are merged first, so the files of higher priority override them. " +
+	"Globs without a priority have priority `0`. Files of the same priority are merged in order of their path relative to the level directory. " +
+	"Priorities set on the provider for globs a data source does not use are ignored."
+
+const numericPrefixOrderDescription = "Within a level and a priority, merge the files in order of the number their name starts with, " +
+	"as in `10-base.yaml` and `50-team.yaml` (`9-` coming before `10-`), before ordering them by path. " +
+	"Files without a numeric prefix come first. Defaults to `false`."
+
+// configGlobsDiagnostics checks the globs of config_globs. In compatibility mode the ignored directory components are
+// reported as warnings, otherwise the globs have to be valid patterns relative to the level directories.
+func configGlobsDiagnostics(configGlobs []string, compat bool) (diags diag.Diagnostics) {
+	for i, glob := range configGlobs {
+		p := path.Root("config_globs").AtListIndex(i)
+		if !compat {
+			if err := finder.ValidateGlob(glob); err != nil {
+				diags.AddAttributeError(p, "Invalid Config Glob", err.Error())
+			}
+			continue
+		}
+		if dir := finder.IgnoredGlobComponents(glob); dir != "" {
+			diags.AddAttributeWarning(p, "Ignored Config Glob Components",
+				fmt.Sprintf("config_globs_compat only uses the last segment of config glob %q, %q is ignored. "+
+					"Unset config_globs_compat to match files in subdirectories.", glob, dir))
+		}
+	}
+	return diags
+}
+
+// unknownGlobPriorities reports the priorities that are not set on one of the globs. It is only used for the
+// priorities and globs of the same configuration: the priorities a data source inherits from the provider can be set
+// on globs the data source does not use, and are ignored for them.
+func unknownGlobPriorities(configGlobs []string, priorities map[string]int) (diags diag.Diagnostics) {
+	globs := make(map[string]bool, len(configGlobs))
+	for _, glob := range configGlobs {
+		globs[glob] = true
+	}
+	for glob := range priorities {
+		if !globs[glob] {
+			diags.AddAttributeError(path.Root("config_globs_priority").AtMapKey(glob), "Unknown Config Glob",
+				fmt.Sprintf("config_globs_priority holds %q, which is not one of config_globs %q", glob, configGlobs))
+		}
+	}
+	return diags
+}
+
+// validateConfigGlobsPriority checks that the priorities of config_globs_priority are set on globs of config_globs,
+// when both are set in the configuration. Unknown values are skipped as they will be validated once known.
+func validateConfigGlobsPriority(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	var globList types.List
+	var priorityMap types.Map
+	diags.Append(config.GetAttribute(ctx, path.Root("config_globs"), &globList)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("config_globs_priority"), &priorityMap)...)
+	if diags.HasError() || globList.IsNull() || globList.IsUnknown() || priorityMap.IsNull() || priorityMap.IsUnknown() {
+		return diags
+	}
+	configGlobs := make([]string, 0, len(globList.Elements()))
+	for _, elem := range globList.Elements() {
+		glob, ok := elem.(types.String)
+		if !ok || glob.IsUnknown() {
+			return diags
+		}
+		configGlobs = append(configGlobs, glob.ValueString())
+	}
+	priorities := make(map[string]int, len(priorityMap.Elements()))
+	for glob := range priorityMap.Elements() {
+		priorities[glob] = 0
+	}
+	return unknownGlobPriorities(configGlobs, priorities)
+}
+
+// globPriorities converts the priorities of config_globs_priority, nil when not set.
+func globPriorities(models map[string]types.Int64) map[string]int {
+	if models == nil {
+		return nil
+	}
+	priorities := make(map[string]int, len(models))
+	for glob, priority := range models {
+		priorities[glob] = int(priority.ValueInt64())
+	}
+	return priorities
+}
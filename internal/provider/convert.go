@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
+)
+
+// toTerraformValue converts a tree produced by spruce into a Terraform value.
+// Maps become objects, lists become tuples and scalars keep their type. Map keys that are not strings are converted
+// to their string representation, as Terraform object attribute names are always strings.
+func toTerraformValue(ctx context.Context, in interface{}) (attr.Value, error) {
+	converted, err := merger.StringKeys(in)
+	if err != nil {
+		return nil, err
+	}
+	return convertValue(ctx, converted, "")
+}
+
+func convertValue(ctx context.Context, in interface{}, path string) (attr.Value, error) {
+	switch v := in.(type) {
+	case nil:
+		return types.StringNull(), nil
+	case string:
+		return types.StringValue(v), nil
+	case bool:
+		return types.BoolValue(v), nil
+	case int:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case int8:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case int16:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case int32:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case int64:
+		return types.NumberValue(new(big.Float).SetInt64(v)), nil
+	case uint:
+		return types.NumberValue(new(big.Float).SetUint64(uint64(v))), nil
+	case uint8:
+		return types.NumberValue(new(big.Float).SetUint64(uint64(v))), nil
+	case uint16:
+		return types.NumberValue(new(big.Float).SetUint64(uint64(v))), nil
+	case uint32:
+		return types.NumberValue(new(big.Float).SetUint64(uint64(v))), nil
+	case uint64:
+		return types.NumberValue(new(big.Float).SetUint64(v)), nil
+	case float32:
+		return convertFloat(float64(v), path)
+	case float64:
+		return convertFloat(v, path)
+	case *big.Int:
+		return types.NumberValue(new(big.Float).SetInt(v)), nil
+	case *big.Float:
+		return types.NumberValue(new(big.Float).Copy(v)), nil
+	case []interface{}:
+		return convertList(ctx, v, path)
+	case map[string]interface{}:
+		return convertMap(ctx, v, path)
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T at %q", in, displayPath(path))
+	}
+}
+
+func convertFloat(f float64, path string) (attr.Value, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("value %v at %q can not be represented as a Terraform number", f, displayPath(path))
+	}
+	return types.NumberValue(big.NewFloat(f)), nil
+}
+
+func convertList(ctx context.Context, in []interface{}, path string) (attr.Value, error) {
+	elemTypes := make([]attr.Type, len(in))
+	elems := make([]attr.Value, len(in))
+	for i, value := range in {
+		converted, err := convertValue(ctx, value, joinPath(path, fmt.Sprintf("%d", i)))
+		if err != nil {
+			return nil, err
+		}
+		elemTypes[i] = converted.Type(ctx)
+		elems[i] = converted
+	}
+	tuple, diags := types.TupleValue(elemTypes, elems)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unable to build list at %q: %v", displayPath(path), diags)
+	}
+	return tuple, nil
+}
+
+func convertMap(ctx context.Context, in map[string]interface{}, path string) (attr.Value, error) {
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrTypes := make(map[string]attr.Type, len(in))
+	attrs := make(map[string]attr.Value, len(in))
+	for _, key := range keys {
+		converted, err := convertValue(ctx, in[key], joinPath(path, key))
+		if err != nil {
+			return nil, err
+		}
+		attrTypes[key] = converted.Type(ctx)
+		attrs[key] = converted
+	}
+	object, diags := types.ObjectValue(attrTypes, attrs)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unable to build object at %q: %v", displayPath(path), diags)
+	}
+	return object, nil
+}
+
+// fromTerraformValue converts a Terraform value into a tree as produced by spruce, the inverse of toTerraformValue.
+// Objects and maps become maps, lists, sets and tuples become lists, numbers become integers when they are integral and
+// fit in an int64 and floats otherwise, and null values become nil. Unknown values can not be converted.
+func fromTerraformValue(ctx context.Context, in attr.Value) (interface{}, error) {
+	return convertTerraformValue(ctx, in, "")
+}
+
+func convertTerraformValue(ctx context.Context, in attr.Value, path string) (interface{}, error) {
+	if in == nil || in.IsNull() {
+		return nil, nil
+	}
+	if in.IsUnknown() {
+		return nil, fmt.Errorf("value at %q is unknown", displayPath(path))
+	}
+	switch v := in.(type) {
+	case types.Dynamic:
+		return convertTerraformValue(ctx, v.UnderlyingValue(), path)
+	case types.String:
+		return v.ValueString(), nil
+	case types.Bool:
+		return v.ValueBool(), nil
+	case types.Int64:
+		return v.ValueInt64(), nil
+	case types.Float64:
+		return v.ValueFloat64(), nil
+	case types.Number:
+		return convertNumber(v.ValueBigFloat()), nil
+	case types.Object:
+		return convertTerraformMap(ctx, v.Attributes(), path)
+	case types.Map:
+		return convertTerraformMap(ctx, v.Elements(), path)
+	case types.List:
+		return convertTerraformList(ctx, v.Elements(), path)
+	case types.Set:
+		return convertTerraformList(ctx, v.Elements(), path)
+	case types.Tuple:
+		return convertTerraformList(ctx, v.Elements(), path)
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T at %q", in, displayPath(path))
+	}
+}
+
+func convertNumber(f *big.Float) interface{} {
+	if f.IsInt() {
+		if i, accuracy := f.Int64(); accuracy == big.Exact {
+			return int(i)
+		}
+	}
+	value, _ := f.Float64()
+	return value
+}
+
+func convertTerraformMap(ctx context.Context, in map[string]attr.Value, path string) (interface{}, error) {
+	out := make(map[interface{}]interface{}, len(in))
+	for key, value := range in {
+		converted, err := convertTerraformValue(ctx, value, joinPath(path, key))
+		if err != nil {
+			return nil, err
+		}
+		out[key] = converted
+	}
+	return out, nil
+}
+
+func convertTerraformList(ctx context.Context, in []attr.Value, path string) (interface{}, error) {
+	out := make([]interface{}, len(in))
+	for i, value := range in {
+		converted, err := convertTerraformValue(ctx, value, joinPath(path, fmt.Sprintf("%d", i)))
+		if err != nil {
+			return nil, err
+		}
+		out[i] = converted
+	}
+	return out, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath returns the dotted key path used in error messages, "." being the root of the document.
+func displayPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+// dynamicObject converts a dynamic value holding an object or a map, nil when the value is null.
+func dynamicObject(ctx context.Context, in types.Dynamic) (map[interface{}]interface{}, error) {
+	if in.IsNull() || in.IsUnderlyingValueNull() {
+		return nil, nil
+	}
+	converted, err := fromTerraformValue(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	out, ok := converted.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value needs to be an object or a map")
+	}
+	return out, nil
+}
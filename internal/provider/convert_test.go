@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestToTerraformValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      interface{}
+		want    attr.Value
+		wantErr bool
+	}{
+		{
+			name: "Scalars",
+			in: map[interface{}]interface{}{
+				"string": "value",
+				"bool":   true,
+				"int":    42,
+				"float":  1.5,
+				"null":   nil,
+			},
+			want: types.ObjectValueMust(
+				map[string]attr.Type{
+					"string": types.StringType,
+					"bool":   types.BoolType,
+					"int":    types.NumberType,
+					"float":  types.NumberType,
+					"null":   types.StringType,
+				},
+				map[string]attr.Value{
+					"string": types.StringValue("value"),
+					"bool":   types.BoolValue(true),
+					"int":    types.NumberValue(big.NewFloat(42)),
+					"float":  types.NumberValue(big.NewFloat(1.5)),
+					"null":   types.StringNull(),
+				},
+			),
+		},
+		{
+			name: "NonStringKeys",
+			in: map[interface{}]interface{}{
+				1:    "one",
+				true: "yes",
+			},
+			want: types.ObjectValueMust(
+				map[string]attr.Type{
+					"1":    types.StringType,
+					"true": types.StringType,
+				},
+				map[string]attr.Value{
+					"1":    types.StringValue("one"),
+					"true": types.StringValue("yes"),
+				},
+			),
+		},
+		{
+			name: "BigInteger",
+			in:   uint64(math.MaxUint64),
+			want: types.NumberValue(new(big.Float).SetUint64(math.MaxUint64)),
+		},
+		{
+			name: "MixedList",
+			in:   []interface{}{"a", 1, []interface{}{false}},
+			want: types.TupleValueMust(
+				[]attr.Type{types.StringType, types.NumberType, types.TupleType{ElemTypes: []attr.Type{types.BoolType}}},
+				[]attr.Value{
+					types.StringValue("a"),
+					types.NumberValue(big.NewFloat(1)),
+					types.TupleValueMust([]attr.Type{types.BoolType}, []attr.Value{types.BoolValue(false)}),
+				},
+			),
+		},
+		{
+			name:    "DuplicateKeysAfterConversion",
+			in:      map[interface{}]interface{}{1: "int", "1": "string"},
+			wantErr: true,
+		},
+		{
+			name:    "NaN",
+			in:      math.NaN(),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toTerraformValue(context.Background(), tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("toTerraformValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("toTerraformValue() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromTerraformValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      attr.Value
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Object",
+			in: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"string": types.StringType,
+					"int":    types.NumberType,
+					"float":  types.NumberType,
+					"null":   types.StringType,
+					"list":   types.ListType{ElemType: types.BoolType},
+				},
+				map[string]attr.Value{
+					"string": types.StringValue("value"),
+					"int":    types.NumberValue(big.NewFloat(42)),
+					"float":  types.NumberValue(big.NewFloat(1.5)),
+					"null":   types.StringNull(),
+					"list":   types.ListValueMust(types.BoolType, []attr.Value{types.BoolValue(true)}),
+				},
+			)),
+			want: map[interface{}]interface{}{
+				"string": "value",
+				"int":    42,
+				"float":  1.5,
+				"null":   nil,
+				"list":   []interface{}{true},
+			},
+		},
+		{
+			name: "Map",
+			in:   types.MapValueMust(types.StringType, map[string]attr.Value{"key": types.StringValue("value")}),
+			want: map[interface{}]interface{}{"key": "value"},
+		},
+		{
+			name: "BigInteger",
+			in:   types.NumberValue(new(big.Float).SetUint64(math.MaxUint64)),
+			want: float64(math.MaxUint64),
+		},
+		{
+			name:    "Unknown",
+			in:      types.ObjectValueMust(map[string]attr.Type{"key": types.StringType}, map[string]attr.Value{"key": types.StringUnknown()}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fromTerraformValue(context.Background(), tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fromTerraformValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fromTerraformValue() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &DeepMergeFunction{}
+
+func NewDeepMergeFunction() function.Function {
+	return &DeepMergeFunction{}
+}
+
+// DeepMergeFunction merges objects with the spruce merge semantics.
+type DeepMergeFunction struct{}
+
+func (f *DeepMergeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "deep_merge"
+}
+
+func (f *DeepMergeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Merge objects with the spruce merge semantics",
+		MarkdownDescription: "Deep merges the objects in order, later objects taking precedence, the same way config files are merged: " +
+			"maps are merged recursively, lists of maps are merged by their `name` key and spruce operators such as " +
+			"`(( grab path ))` are evaluated once every object is merged. Objects and maps are accepted.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "a",
+				MarkdownDescription: "The first object to merge.",
+			},
+			function.DynamicParameter{
+				Name:                "b",
+				MarkdownDescription: "The object to merge over the first one.",
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "objects",
+			MarkdownDescription: "More objects to merge, in order.",
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *DeepMergeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var a, b types.Dynamic
+	var objects []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &a, &b, &objects)
+	if resp.Error != nil {
+		return
+	}
+
+	trees := make([]map[interface{}]interface{}, 0, len(objects)+2)
+	for i, arg := range append([]types.Dynamic{a, b}, objects...) {
+		if arg.IsNull() || arg.IsUnderlyingValueNull() {
+			continue
+		}
+		converted, err := fromTerraformValue(ctx, arg)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(int64(i), err.Error())
+			return
+		}
+		tree, ok := converted.(map[interface{}]interface{})
+		if !ok {
+			resp.Error = function.NewArgumentFuncError(int64(i), fmt.Sprintf("argument %d is not an object or a map", i+1))
+			return
+		}
+		trees = append(trees, tree)
+	}
+
+	ev, err := merger.MergeTrees(trees, merger.MergeOpts{})
+	if err != nil {
+		resp.Error = function.NewFuncError("Unable to merge the objects, got error: " + err.Error())
+		return
+	}
+
+	value, err := toTerraformValue(ctx, ev.Tree)
+	if err != nil {
+		resp.Error = function.NewFuncError("Unable to convert the merged result, got error: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(value))
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDeepMergeFunction(t *testing.T) {
+	object := func(attrs map[string]attr.Value) types.Object {
+		attrTypes := make(map[string]attr.Type, len(attrs))
+		for name, value := range attrs {
+			attrTypes[name] = value.Type(context.Background())
+		}
+		return types.ObjectValueMust(attrTypes, attrs)
+	}
+	tests := []struct {
+		name    string
+		args    []attr.Value
+		want    attr.Value
+		wantErr bool
+	}{
+		{
+			name: "NestedObjects",
+			args: []attr.Value{
+				types.DynamicValue(object(map[string]attr.Value{
+					"a": object(map[string]attr.Value{"x": types.NumberValue(big.NewFloat(1))}),
+				})),
+				types.DynamicValue(object(map[string]attr.Value{
+					"a": object(map[string]attr.Value{"y": types.StringValue("two")}),
+				})),
+				types.TupleValueMust([]attr.Type{}, []attr.Value{}),
+			},
+			want: types.DynamicValue(object(map[string]attr.Value{
+				"a": object(map[string]attr.Value{
+					"x": types.NumberValue(big.NewFloat(1)),
+					"y": types.StringValue("two"),
+				}),
+			})),
+		},
+		{
+			name: "OperatorsAndVariadic",
+			args: []attr.Value{
+				types.DynamicValue(object(map[string]attr.Value{
+					"key": types.StringValue("first"),
+					"ref": types.StringValue("(( grab key ))"),
+				})),
+				types.DynamicValue(types.MapValueMust(types.StringType, map[string]attr.Value{
+					"key": types.StringValue("second"),
+				})),
+				types.TupleValueMust(
+					[]attr.Type{types.DynamicType},
+					[]attr.Value{types.DynamicValue(object(map[string]attr.Value{
+						"key": types.StringValue("third"),
+					}))},
+				),
+			},
+			want: types.DynamicValue(object(map[string]attr.Value{
+				"key": types.StringValue("third"),
+				"ref": types.StringValue("third"),
+			})),
+		},
+		{
+			name: "NotAnObject",
+			args: []attr.Value{
+				types.DynamicValue(object(map[string]attr.Value{})),
+				types.DynamicValue(types.StringValue("value")),
+				types.TupleValueMust([]attr.Type{}, []attr.Value{}),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := function.RunResponse{
+				Result: function.NewResultData(types.DynamicUnknown()),
+			}
+			(&DeepMergeFunction{}).Run(context.Background(), function.RunRequest{
+				Arguments: function.NewArgumentsData(tt.args),
+			}, &resp)
+			if (resp.Error != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", resp.Error, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !resp.Result.Value().Equal(tt.want) {
+				t.Errorf("Run() got = %v, want %v", resp.Result.Value(), tt.want)
+			}
+		})
+	}
+}
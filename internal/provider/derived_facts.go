@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+)
+
+// DerivedFactModel describes a fact computed from the facts discovered from the path.
+type DerivedFactModel struct {
+	Name     types.String `tfsdk:"name"`
+	Fact     types.String `tfsdk:"fact"`
+	Lookup   types.Map    `tfsdk:"lookup"`
+	Default  types.String `tfsdk:"default"`
+	Template types.String `tfsdk:"template"`
+}
+
+var derivedFactsAttribute = schema.ListNestedAttribute{
+	MarkdownDescription: "Facts computed from the facts discovered from the path, either by looking the value of a fact up " +
+		"in `lookup`, or by rendering `template`. They are computed in order, so a derived fact can use the ones before it, " +
+		"and added to the facts of every data source. A derived fact using a fact that is not set, such as the fact of a skipped " +
+		"optional level or of a project structure overridden on the data source, is set to its `default`, or else skipped.",
+	Optional: true,
+	NestedObject: schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the derived fact, for example `facts.region_short`. Can not be the name of a fact that is already set.",
+				Required:            true,
+			},
+			"fact": schema.StringAttribute{
+				MarkdownDescription: "Name of the fact whose value is looked up in `lookup`, for example `facts.region`.",
+				Optional:            true,
+			},
+			"lookup": schema.MapAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "Value of the derived fact, indexed by the value of `fact`.",
+				Optional:            true,
+			},
+			"default": schema.StringAttribute{
+				MarkdownDescription: "Value of the derived fact when the value of `fact` is not in `lookup`, or when `fact` is not set. " +
+					"Values missing from `lookup` are an error when not set.",
+				Optional: true,
+			},
+			"template": schema.StringAttribute{
+				MarkdownDescription: "Template rendered by replacing every `{{name}}` with the value of the fact, " +
+					"for example `{{facts.environment}}-{{facts.region_short}}`.",
+				Optional: true,
+			},
+		},
+	},
+}
+
+// derivedFacts converts the derived facts models.
+func derivedFacts(ctx context.Context, models []DerivedFactModel) (facts []envfacts.DerivedFact, diags diag.Diagnostics) {
+	facts = make([]envfacts.DerivedFact, len(models))
+	for i, m := range models {
+		facts[i] = envfacts.DerivedFact{
+			Name:     m.Name.ValueString(),
+			Fact:     m.Fact.ValueString(),
+			Default:  m.Default.ValueString(),
+			Template: m.Template.ValueString(),
+		}
+		if !m.Lookup.IsNull() {
+			facts[i].Lookup = make(map[string]string)
+			diags.Append(m.Lookup.ElementsAs(ctx, &facts[i].Lookup, false)...)
+		}
+	}
+	return facts, diags
+}
+
+// validateDerivedFacts checks that every derived fact is either a lookup or a template.
+// Derived facts holding unknown values are skipped as they will be validated once known.
+func validateDerivedFacts(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	var list types.List
+	diags.Append(config.GetAttribute(ctx, path.Root("derived_facts"), &list)...)
+	if diags.HasError() || list.IsNull() || list.IsUnknown() {
+		return diags
+	}
+	models := make([]types.Object, 0, len(list.Elements()))
+	diags.Append(list.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	for i, obj := range models {
+		if obj.IsUnknown() {
+			continue
+		}
+		var m DerivedFactModel
+		diags.Append(obj.As(ctx, &m, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+		if m.Name.IsUnknown() || m.Fact.IsUnknown() || m.Lookup.IsUnknown() || m.Default.IsUnknown() || m.Template.IsUnknown() {
+			continue
+		}
+		facts, d := derivedFacts(ctx, []DerivedFactModel{m})
+		diags.Append(d...)
+		if d.HasError() {
+			continue
+		}
+		if err := facts[0].Validate(); err != nil {
+			diags.AddAttributeError(path.Root("derived_facts").AtListIndex(i), "Invalid Derived Fact", err.Error())
+		}
+	}
+	return diags
+}
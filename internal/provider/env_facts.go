@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultEnvFactsKey is the key the environment variables are added under, when env_facts_key is not set.
+const defaultEnvFactsKey = "env"
+
+// EnvFactModel describes an environment variable exposed as a fact.
+type EnvFactModel struct {
+	Name     types.String `tfsdk:"name"`
+	Default  types.String `tfsdk:"default"`
+	Required types.Bool   `tfsdk:"required"`
+}
+
+var envFactsAttribute = schema.ListNestedAttribute{
+	MarkdownDescription: "Environment variables exposed as facts, under `env_facts_key`. Only the listed variables are exposed, " +
+		"unset variables being left out unless they have a default value.",
+	Optional: true,
+	NestedObject: schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the environment variable, for example `BUILD_NUMBER`.",
+				Required:            true,
+			},
+			"default": schema.StringAttribute{
+				MarkdownDescription: "Value of the fact when the environment variable is not set.",
+				Optional:            true,
+			},
+			"required": schema.BoolAttribute{
+				MarkdownDescription: "Fail when the environment variable is not set. Can not be used along with `default`. Defaults to `false`.",
+				Optional:            true,
+			},
+		},
+	},
+}
+
+const envFactsKeyDescription = "Dotted key the environment variables of `env_facts` are added under, for example `facts.env`. " +
+	"Defaults to `" + defaultEnvFactsKey + "`."
+
+// envFacts returns the facts of the environment variables, indexed by their dotted name, looking the variables up
+// with lookupEnv.
+func envFacts(models []EnvFactModel, key string, lookupEnv func(string) (string, bool)) (map[string]interface{}, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+	if key == "" {
+		key = defaultEnvFactsKey
+	}
+	facts := make(map[string]interface{}, len(models))
+	for _, m := range models {
+		name := m.Name.ValueString()
+		value, ok := lookupEnv(name)
+		switch {
+		case ok:
+		case !m.Default.IsNull():
+			value = m.Default.ValueString()
+		case m.Required.ValueBool():
+			return nil, fmt.Errorf("required environment variable %q is not set", name)
+		default:
+			continue
+		}
+		facts[key+"."+name] = value
+	}
+	return facts, nil
+}
+
+// validateEnvFacts checks the names of the environment variables, and that required ones have no default.
+func validateEnvFacts(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	var key types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("env_facts_key"), &key)...)
+	if !key.IsNull() && !key.IsUnknown() {
+		k := key.ValueString()
+		if k == "" || strings.HasPrefix(k, ".") || strings.HasSuffix(k, ".") || strings.Contains(k, "..") {
+			diags.AddAttributeError(path.Root("env_facts_key"), "Invalid Environment Facts Key",
+				fmt.Sprintf("env_facts_key needs to be a dotted key such as `env` or `facts.env`, got %q", k))
+		}
+	}
+
+	var list types.List
+	diags.Append(config.GetAttribute(ctx, path.Root("env_facts"), &list)...)
+	if diags.HasError() || list.IsNull() || list.IsUnknown() {
+		return diags
+	}
+	var models []EnvFactModel
+	diags.Append(list.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	seen := make(map[string]bool)
+	for i, m := range models {
+		p := path.Root("env_facts").AtListIndex(i)
+		if m.Name.IsUnknown() {
+			continue
+		}
+		name := m.Name.ValueString()
+		switch {
+		case name == "" || strings.ContainsAny(name, "=."):
+			diags.AddAttributeError(p.AtName("name"), "Invalid Environment Fact",
+				fmt.Sprintf("environment variable name can not be empty or hold `=` or `.`, got %q", name))
+		case seen[name]:
+			diags.AddAttributeError(p.AtName("name"), "Invalid Environment Fact", fmt.Sprintf("environment variable %q is listed more than once", name))
+		}
+		seen[name] = true
+		if m.Required.ValueBool() && !m.Default.IsNull() {
+			diags.AddAttributeError(p, "Invalid Environment Fact", fmt.Sprintf("environment variable %q can not be required and have a default", name))
+		}
+	}
+	return diags
+}
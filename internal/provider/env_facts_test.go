@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestEnvFacts(t *testing.T) {
+	env := map[string]string{"BUILD_NUMBER": "42", "DEPLOY_RING": "canary"}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	tests := []struct {
+		name    string
+		models  []EnvFactModel
+		key     string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "DefaultKey",
+			models: []EnvFactModel{
+				{Name: types.StringValue("BUILD_NUMBER"), Default: types.StringNull(), Required: types.BoolValue(true)},
+				{Name: types.StringValue("DEPLOY_RING"), Default: types.StringNull(), Required: types.BoolNull()},
+			},
+			want: map[string]interface{}{"env.BUILD_NUMBER": "42", "env.DEPLOY_RING": "canary"},
+		},
+		{
+			name: "DefaultAndUnset",
+			models: []EnvFactModel{
+				{Name: types.StringValue("REGION_OVERRIDE"), Default: types.StringValue("none"), Required: types.BoolNull()},
+				{Name: types.StringValue("UNSET"), Default: types.StringNull(), Required: types.BoolNull()},
+			},
+			key:  "facts.ci",
+			want: map[string]interface{}{"facts.ci.REGION_OVERRIDE": "none"},
+		},
+		{
+			name: "RequiredUnset",
+			models: []EnvFactModel{
+				{Name: types.StringValue("UNSET"), Default: types.StringNull(), Required: types.BoolValue(true)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "None",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := envFacts(tt.models, tt.key, lookupEnv)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("envFacts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("envFacts() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &FactsFunction{}
+
+func NewFactsFunction() function.Function {
+	return &FactsFunction{}
+}
+
+// FactsFunction returns the facts discovered from a path, without merging any config file.
+type FactsFunction struct{}
+
+func (f *FactsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "facts"
+}
+
+func (f *FactsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Return the facts discovered from a path",
+		MarkdownDescription: "Maps `config_path` onto the project structure and returns the facts it defines as an object, " +
+			"for example `{ facts = { environment = \"production\" } }` for the `config/{{facts.environment}}` structure. " +
+			"No config file is read.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "config_path",
+				MarkdownDescription: "The path to discover the facts from.",
+			},
+			function.StringParameter{
+				Name:                "project_config",
+				MarkdownDescription: "The project structure, for example `config/{{facts.environment}}/{{facts.region}}/{{facts.project}}`.",
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *FactsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var configPath, projectConfig string
+
+	resp.Error = req.Arguments.Get(ctx, &configPath, &projectConfig)
+	if resp.Error != nil {
+		return
+	}
+
+	if _, err := envfacts.ParseProjectStructure(projectConfig); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	p, _, err := mapProject(ctx, configPath, []string{projectConfig}, "", nil)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	facts, err := p.Facts()
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	value, err := toTerraformValue(ctx, facts)
+	if err != nil {
+		resp.Error = function.NewFuncError("Unable to convert the facts, got error: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(value))
+}
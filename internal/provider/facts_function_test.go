@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestFactsFunction(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []attr.Value
+		want    attr.Value
+		wantErr bool
+	}{
+		{
+			name: "Facts",
+			args: []attr.Value{
+				types.StringValue("../../tests/config/production/us-west-2/s3bucket"),
+				types.StringValue("config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"),
+			},
+			want: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"facts": types.ObjectType{AttrTypes: map[string]attr.Type{
+						"environment": types.StringType,
+						"project":     types.StringType,
+						"region":      types.StringType,
+					}},
+				},
+				map[string]attr.Value{
+					"facts": types.ObjectValueMust(
+						map[string]attr.Type{
+							"environment": types.StringType,
+							"project":     types.StringType,
+							"region":      types.StringType,
+						},
+						map[string]attr.Value{
+							"environment": types.StringValue("production"),
+							"project":     types.StringValue("s3bucket"),
+							"region":      types.StringValue("us-west-2"),
+						},
+					),
+				},
+			)),
+		},
+		{
+			name: "InvalidProjectConfig",
+			args: []attr.Value{
+				types.StringValue("../../tests/config/production/us-west-2/s3bucket"),
+				types.StringValue("config/{{facts.environment"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "PathOutsideProject",
+			args: []attr.Value{
+				types.StringValue("/"),
+				types.StringValue("config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := function.RunResponse{
+				Result: function.NewResultData(types.DynamicUnknown()),
+			}
+			(&FactsFunction{}).Run(context.Background(), function.RunRequest{
+				Arguments: function.NewArgumentsData(tt.args),
+			}, &resp)
+			if (resp.Error != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", resp.Error, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !resp.Result.Value().Equal(tt.want) {
+				t.Errorf("Run() got = %v, want %v", resp.Result.Value(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gookit/goutil/maputil"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &LeavesDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &LeavesDataSource{}
+
+func NewLeavesDataSource() datasource.DataSource {
+	return &LeavesDataSource{}
+}
+
+// LeavesDataSource enumerates the directories that match the project structure.
+type LeavesDataSource struct {
+	projectConfigs []string
+	rootDir        string
+	baseDir        string
+	derivedFacts   []envfacts.DerivedFact
+	envFacts       []EnvFactModel
+	envFactsKey    string
+	allowedValues  map[string]envfacts.AllowedValues
+}
+
+// LeavesDataSourceModel describes the data source data model.
+type LeavesDataSourceModel struct {
+	Id             types.String            `tfsdk:"id"`
+	RootPath       types.String            `tfsdk:"root_path"`
+	RootDir        types.String            `tfsdk:"root_dir"`
+	BaseDir        types.String            `tfsdk:"base_dir"`
+	ProjectConfig  types.String            `tfsdk:"project_config"`
+	ProjectConfigs []types.String          `tfsdk:"project_configs"`
+	Filter         map[string]types.String `tfsdk:"filter"`
+	Leaves         map[string]LeafModel    `tfsdk:"leaves"`
+}
+
+// LeafModel describes a directory that matches the project structure.
+type LeafModel struct {
+	Path          types.String            `tfsdk:"path"`
+	ProjectConfig types.String            `tfsdk:"project_config"`
+	Facts         map[string]types.String `tfsdk:"facts"`
+}
+
+func (d *LeavesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_leaves"
+}
+
+func (d *LeavesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the directories that fully match the project structure, along with their facts, " +
+			"to be used with `for_each`.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the root directory.",
+				Computed:            true,
+			},
+			"root_path": schema.StringAttribute{
+				MarkdownDescription: "Path to the root directory of the project structure, for example `config` for " +
+					"`config/{{facts.environment}}/{{facts.region}}`. Its name must match the root of the project structure, " +
+					"unless it holds a `" + envfacts.RootMarker + "` file or `root_dir` is set to it.",
+				Required: true,
+			},
+			"root_dir": schema.StringAttribute{
+				MarkdownDescription: rootDirDescription + " `root_path` has to be the root directory when set. Overrides the provider setting.",
+				Optional:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: baseDirDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"project_config": schema.StringAttribute{
+				MarkdownDescription: "Project Configuration. Overrides the provider setting.",
+				Optional:            true,
+			},
+			"project_configs": schema.ListAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: projectConfigsDescription + " The leaves of every structure whose root matches `root_path` " +
+					"are listed, the first structure winning when several of them find the same directory. Overrides the provider setting.",
+				Optional: true,
+			},
+			"filter": schema.MapAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "Only keep the directories whose facts match, indexed by the fact name as written in the " +
+					"project structure (for example `facts.environment`, or `facts.region_groups.geo` for a named capture group). " +
+					"Values are glob patterns (for example `us-*`).",
+				Optional: true,
+			},
+			"leaves": schema.MapNestedAttribute{
+				MarkdownDescription: "The matching directories, indexed by their path relative to the root directory " +
+					"(for example `production/us-west-2`).",
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Path of the directory, `root_path` joined with the relative path. " +
+								"Can be used as `config_path` of the `config-merger_result` data source.",
+							Computed: true,
+						},
+						"project_config": schema.StringAttribute{
+							MarkdownDescription: "The project structure that found the directory.",
+							Computed:            true,
+						},
+						"facts": schema.MapAttribute{
+							ElementType: types.StringType,
+							MarkdownDescription: "Value of every fact, indexed by the fact name as written in the project structure. " +
+								"Missing optional levels are left out, unless they have a default value. " +
+								"The named capture groups of a constraint are included as `name_groups.group`, along with the value of the variable. " +
+								"The derived facts of the provider are included as well. " +
+								"Typed facts are written in their canonical form (`true` for a `TRUE` directory, `7` for `007`).",
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *LeavesDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateProjectConfig(ctx, req.Config)...)
+
+	var filter types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("filter"), &filter)...)
+	if resp.Diagnostics.HasError() || filter.IsNull() || filter.IsUnknown() {
+		return
+	}
+	for name, elem := range filter.Elements() {
+		value, ok := elem.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		if _, err := filepath.Match(value.ValueString(), ""); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("filter").AtMapKey(name), "Invalid Filter Pattern",
+				fmt.Sprintf("pattern %q: %s", value.ValueString(), err))
+		}
+	}
+}
+
+func (d *LeavesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerConfig, ok := req.ProviderData.(ConfigMergerProviderModel)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ConfigMergerProviderModel, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.projectConfigs = projectConfigList(providerConfig.ProjectConfig, providerConfig.ProjectConfigs)
+	d.rootDir = providerConfig.RootDir.ValueString()
+	d.baseDir = providerConfig.BaseDir.ValueString()
+	var diags diag.Diagnostics
+	d.derivedFacts, diags = derivedFacts(ctx, providerConfig.DerivedFacts)
+	resp.Diagnostics.Append(diags...)
+	d.envFacts = providerConfig.EnvFacts
+	d.envFactsKey = providerConfig.EnvFactsKey.ValueString()
+	d.allowedValues, diags = allowedValues(ctx, providerConfig.AllowedValues)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *LeavesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data LeavesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	projectConfigs := d.projectConfigs
+	if list := projectConfigList(data.ProjectConfig, data.ProjectConfigs); list != nil {
+		projectConfigs = list
+	}
+	if len(projectConfigs) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("project_config"),
+			"Missing Project Configuration",
+			"project_config or project_configs needs to be set either on the provider or on the data source.",
+		)
+		return
+	}
+
+	structures, err := parseProjectConfigs(projectConfigs)
+	if err == nil {
+		err = setAllowedValues(structures, d.allowedValues)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable parse project, got error: %s", err))
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, p := range structures {
+		for _, v := range p.Vars {
+			if v.IsLiteral() {
+				continue
+			}
+			names[v.VariableName] = true
+			for _, group := range v.Groups() {
+				names[v.GroupsName()+"."+group] = true
+			}
+		}
+	}
+	for _, derived := range d.derivedFacts {
+		names[derived.Name] = true
+	}
+	filterNames := make([]string, 0, len(data.Filter))
+	for name := range data.Filter {
+		if !names[name] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filter").AtMapKey(name),
+				"Unknown Fact",
+				fmt.Sprintf("fact %q is not part of the project structures %q", name, projectConfigs),
+			)
+		}
+		filterNames = append(filterNames, name)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sort.Strings(filterNames)
+
+	rootPath := data.RootPath.ValueString()
+	baseDir := d.baseDir
+	if !data.BaseDir.IsNull() {
+		baseDir = data.BaseDir.ValueString()
+	}
+	absRoot, err := envfacts.ResolvePath(baseDir, rootPath, os.UserHomeDir)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("root_path"), "Client Error", fmt.Sprintf("Unable to resolve the root path, got error: %s", err))
+		return
+	}
+	rootDir := d.rootDir
+	if !data.RootDir.IsNull() {
+		rootDir = data.RootDir.ValueString()
+	}
+	if rootDir != "" {
+		rootDir, err = envfacts.ResolvePath(baseDir, rootDir, os.UserHomeDir)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("root_dir"), "Client Error", fmt.Sprintf("Unable to resolve the root directory, got error: %s", err))
+			return
+		}
+		for i := range structures {
+			structures[i].RootDir = rootDir
+		}
+	}
+
+	// derived facts can use the environment facts, which are not part of the leaf facts
+	env, err := envFacts(d.envFacts, d.envFactsKey, os.LookupEnv)
+	if err != nil {
+		resp.Diagnostics.AddError("Missing Environment Variable", err.Error())
+		return
+	}
+
+	// the leaves of every structure whose root matches, the first structure winning when several find the same leaf
+	leaves := make(map[string]LeafModel)
+	walked := false
+	for i, p := range structures {
+		isRoot, err := p.IsRoot(absRoot, os.UserHomeDir)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("root_path"), "Client Error", fmt.Sprintf("Unable to resolve the root path, got error: %s", err))
+			return
+		}
+		if !isRoot {
+			continue
+		}
+		walked = true
+		found, err := p.FindLeaves(absRoot, os.UserHomeDir)
+		if notAllowed := notAllowedErrors(err); len(notAllowed) > 0 {
+			for _, e := range notAllowed {
+				resp.Diagnostics.AddAttributeError(path.Root("root_path"), "Invalid Fact Value", e.Error())
+			}
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("root_path"), "Client Error", fmt.Sprintf("Unable to list the leaves, got error: %s", err))
+			return
+		}
+		for _, leaf := range found {
+			levels := leaf.Levels()
+			rel, err := filepath.Rel(levels[0].RealPath, levels[len(levels)-1].RealPath)
+			if err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to compute the leaf path, got error: %s", err))
+				return
+			}
+			rel = filepath.ToSlash(rel)
+			if _, exists := leaves[rel]; exists {
+				continue
+			}
+			facts, err := leafFacts(leaf, env, d.derivedFacts)
+			if err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to compute the facts of %q, got error: %s", rel, err))
+				return
+			}
+			leaves[rel] = LeafModel{
+				Path:          types.StringValue(filepath.Join(rootPath, filepath.FromSlash(rel))),
+				ProjectConfig: types.StringValue(projectConfigs[i]),
+				Facts:         facts,
+			}
+		}
+	}
+	if !walked {
+		msg := fmt.Sprintf("root path %q does not match the root of any of the project structures %q", rootPath, projectConfigs)
+		if rootDir != "" {
+			msg = fmt.Sprintf("root path %q is not the root directory %q", rootPath, rootDir)
+		}
+		resp.Diagnostics.AddAttributeError(path.Root("root_path"), "Client Error", msg)
+		return
+	}
+
+	data.Leaves = make(map[string]LeafModel, len(leaves))
+	for rel, leaf := range leaves {
+		if hasDescendant(leaves, rel) || !matchFilter(data.Filter, filterNames, leaf.Facts) {
+			continue
+		}
+		data.Leaves[rel] = leaf
+	}
+	data.Id = types.StringValue(absRoot)
+
+	tflog.Trace(ctx, "read a data source")
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// leafFacts returns the facts of the leaf, along with the derived facts, indexed by their name as written in the
+// project structure. Typed facts are written in their canonical form, `true` for a `TRUE` directory. The environment
+// facts are only used to compute the derived facts.
+func leafFacts(leaf envfacts.ProjectStructure, env map[string]interface{}, derived []envfacts.DerivedFact) (map[string]types.String, error) {
+	facts := make(map[string]types.String, len(leaf.Vars))
+	for _, v := range leaf.Vars {
+		if v.IsLiteral() || (v.Missing && v.Default == "") {
+			continue
+		}
+		// values were validated when mapping the leaf
+		value, _ := v.TypedValue(v.VariableValue)
+		facts[v.VariableName] = types.StringValue(fmt.Sprint(value))
+		for group, value := range v.SubFacts {
+			facts[v.GroupsName()+"."+group] = types.StringValue(value)
+		}
+	}
+	if len(derived) == 0 {
+		return facts, nil
+	}
+	nested, err := leaf.Facts()
+	if err != nil {
+		return nil, err
+	}
+	if err := envfacts.AddFacts(nested, env); err != nil {
+		return nil, err
+	}
+	if err := envfacts.DeriveFacts(nested, derived); err != nil {
+		return nil, err
+	}
+	for _, d := range derived {
+		value, _ := maputil.GetByPath(strings.TrimPrefix(d.Name, "."), nested)
+		facts[d.Name] = types.StringValue(fmt.Sprint(value))
+	}
+	return facts, nil
+}
+
+// hasDescendant checks if one of the leaves is below rel. Structures of different depths can find a directory and
+// one of its descendants, only the most specific one is kept.
+func hasDescendant(leaves map[string]LeafModel, rel string) bool {
+	prefix := rel + "/"
+	if rel == "." {
+		return len(leaves) > 1
+	}
+	for other := range leaves {
+		if strings.HasPrefix(other, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter checks that the facts match every filter pattern. Facts that are not set never match.
+func matchFilter(filter map[string]types.String, names []string, facts map[string]types.String) bool {
+	for _, name := range names {
+		fact, ok := facts[name]
+		if !ok {
+			return false
+		}
+		if matched, _ := filepath.Match(filter[name].ValueString(), fact.ValueString()); !matched {
+			return false
+		}
+	}
+	return true
+}
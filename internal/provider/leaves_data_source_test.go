@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccLeavesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLeavesDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_leaves.test", "leaves.%", "1"),
+					resource.TestCheckResourceAttr("data.config-merger_leaves.test", "leaves.production/us-west-2/s3bucket.path", "../../tests/config/production/us-west-2/s3bucket"),
+					resource.TestCheckResourceAttr("data.config-merger_leaves.test", "leaves.production/us-west-2/s3bucket.facts.facts.region", "us-west-2"),
+					resource.TestCheckResourceAttr("data.config-merger_leaves.filtered", "leaves.%", "0"),
+				),
+			},
+			{
+				Config:      testAccLeavesDataSourceUnknownFactConfig,
+				ExpectError: regexp.MustCompile(`Unknown Fact`),
+			},
+		},
+	})
+}
+
+const testAccLeavesDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+}
+
+data "config-merger_leaves" "test" {
+  root_path = "../../tests/config"
+}
+
+data "config-merger_leaves" "filtered" {
+  root_path = "../../tests/config"
+  filter = {
+    "facts.environment" = "development"
+  }
+}
+`
+
+const testAccLeavesDataSourceUnknownFactConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+}
+
+data "config-merger_leaves" "test" {
+  root_path = "../../tests/config"
+  filter = {
+    "facts.account" = "*"
+  }
+}
+`
+
+func TestAccLeavesDataSourceAllowedValues(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLeavesDataSourceAllowedValuesConfig,
+				ExpectError: regexp.MustCompile(`valid choices are "development", "staging"`),
+			},
+			{
+				Config: testAccLeavesDataSourceAllowedValuesOverrideConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_leaves.test", "leaves.production/us-west-2.facts.facts.region", "us-west-2"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLeavesDataSourceAllowedValuesConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  allowed_values = {
+    "facts.environment" = { values = ["development", "staging"] }
+  }
+}
+
+data "config-merger_leaves" "test" {
+  root_path = "../../tests/config"
+}
+`
+
+const testAccLeavesDataSourceAllowedValuesOverrideConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  allowed_values = {
+    "facts.environment" = { values = ["production"] }
+    "facts.project"     = { values = ["s3bucket"] }
+  }
+}
+
+data "config-merger_leaves" "test" {
+  root_path      = "../../tests/config"
+  project_config = "config/{{facts.environment}}/{{facts.region}}"
+}
+`
+
+func TestAccLeavesRootDirDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLeavesRootDirDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_leaves.test", "leaves.%", "1"),
+					resource.TestCheckResourceAttr("data.config-merger_leaves.test", "leaves.production/us-west-2/s3bucket.facts.facts.project", "s3bucket"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLeavesRootDirDataSourceConfig = `
+provider "config-merger" {
+  project_config = "hierarchy/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  root_dir       = "../../tests/config"
+}
+
+data "config-merger_leaves" "test" {
+  root_path = "../../tests/config"
+}
+`
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/finder"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &MergeFunction{}
+
+func NewMergeFunction() function.Function {
+	return &MergeFunction{}
+}
+
+// MergeFunction merges the config files of the hierarchy leading to a path, as the config-merger_result data source does.
+type MergeFunction struct{}
+
+func (f *MergeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge"
+}
+
+func (f *MergeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Merge the config files of the hierarchy leading to a path",
+		MarkdownDescription: "Merges the config files found on every level of the hierarchy leading to `config_path` and " +
+			"returns the result as an object, the same way the `config-merger_result` data source does. " +
+			"Functions do not have access to the provider configuration, so the project structure and the config globs " +
+			"have to be passed as arguments, and the merge options use their defaults. " +
+			"Values fetched from a secret store are left out of the result, as function results can not be marked sensitive.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "config_path",
+				MarkdownDescription: "The path to merge the config for.",
+			},
+			function.StringParameter{
+				Name:                "project_config",
+				MarkdownDescription: "The project structure, for example `config/{{facts.environment}}/{{facts.region}}/{{facts.project}}`.",
+			},
+			function.ListParameter{
+				Name:        "config_globs",
+				ElementType: types.StringType,
+				MarkdownDescription: "The globs of the config files to merge on every level, relative to the level directories. " +
+					"Globs can hold subdirectories and `**`, as in the `config_globs` of the provider.",
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *MergeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var configPath, projectConfig string
+	var configGlobs []string
+
+	resp.Error = req.Arguments.Get(ctx, &configPath, &projectConfig, &configGlobs)
+	if resp.Error != nil {
+		return
+	}
+
+	if _, err := envfacts.ParseProjectStructure(projectConfig); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+	if len(configGlobs) == 0 {
+		resp.Error = function.NewArgumentFuncError(2, "config_globs needs at least one glob.")
+		return
+	}
+	for _, glob := range configGlobs {
+		if err := finder.ValidateGlob(glob); err != nil {
+			resp.Error = function.NewArgumentFuncError(2, err.Error())
+			return
+		}
+	}
+
+	result, err := runMerge(ctx, mergeRequest{
+		configPath:     configPath,
+		projectConfigs: []string{projectConfig},
+		configGlobs:    configGlobs,
+	})
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	public, _ := merger.SplitSensitive(result.evaluator.Tree, nil, result.sources)
+	value, err := toTerraformValue(ctx, public)
+	if err != nil {
+		resp.Error = function.NewFuncError("Unable to convert the merged result, got error: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(value))
+}
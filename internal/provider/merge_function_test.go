@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccMergeFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMergeFunctionConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("root_key", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"key_1": knownvalue.StringExact("s3bucket_value_1"),
+						"key_2": knownvalue.StringExact("production-s3bucket_value_2"),
+						"key_3": knownvalue.StringExact("s3bucket_value_1"),
+					})),
+					statecheck.ExpectKnownOutputValue("environment", knownvalue.StringExact("production")),
+					statecheck.ExpectKnownOutputValue("deep_merge", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"key": knownvalue.StringExact("override"),
+						"ref": knownvalue.StringExact("override"),
+					})),
+				},
+			},
+		},
+	})
+}
+
+const testAccMergeFunctionConfig = `
+locals {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_path    = "../../tests/config/production/us-west-2/s3bucket"
+}
+
+output "root_key" {
+  value = provider::config-merger::merge(local.config_path, local.project_config, ["config.yaml", "*.config.yaml"]).root_key
+}
+
+output "environment" {
+  value = provider::config-merger::facts(local.config_path, local.project_config).facts.environment
+}
+
+output "deep_merge" {
+  value = provider::config-merger::deep_merge({ key = "base", ref = "(( grab key ))" }, { key = "override" })
+}
+`
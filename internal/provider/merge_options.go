@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
+)
+
+const (
+	skipEvalDescription       = "Do not evaluate spruce operators after merging the files. Defaults to `false`."
+	pruneDescription          = "Keys to prune from the final result, in spruce path syntax (for example `meta.helpers`)."
+	cherryPickDescription     = "The opposite of `prune`: keys to cherry-pick from the final result, in spruce path syntax."
+	fallbackAppendDescription = "Append lists that can not be merged by key instead of merging them inline. Defaults to `false`."
+	goPatchDescription        = "Treat config files whose root is a list as go-patch operations applied to the result merged so far. Defaults to `false`."
+	multiDocDescription       = "Treat every document of a multi-document config file as its own file, merged in order. Defaults to `false`."
+)
+
+// mergeOptsModel holds the merge options, as they can be set on both the provider and the data source.
+type mergeOptsModel struct {
+	SkipEval       types.Bool
+	Prune          []types.String
+	CherryPick     []types.String
+	FallbackAppend types.Bool
+	GoPatch        types.Bool
+	MultiDoc       types.Bool
+}
+
+// apply returns a copy of base, overridden by the options that are set in m.
+func (m mergeOptsModel) apply(base merger.MergeOpts) merger.MergeOpts {
+	if !m.SkipEval.IsNull() {
+		base.SkipEval = m.SkipEval.ValueBool()
+	}
+	if m.Prune != nil {
+		base.Prune = stringValues(m.Prune)
+	}
+	if m.CherryPick != nil {
+		base.CherryPick = stringValues(m.CherryPick)
+	}
+	if !m.FallbackAppend.IsNull() {
+		base.FallbackAppend = m.FallbackAppend.ValueBool()
+	}
+	if !m.GoPatch.IsNull() {
+		base.EnableGoPatch = m.GoPatch.ValueBool()
+	}
+	if !m.MultiDoc.IsNull() {
+		base.MultiDoc = m.MultiDoc.ValueBool()
+	}
+	return base
+}
+
+// stringValues converts a list of terraform strings to go strings.
+func stringValues(in []types.String) []string {
+	out := make([]string, len(in))
+	for i, v := range in {
+		out[i] = v.ValueString()
+	}
+	return out
+}
+
+// validateMergeOpts validates the key paths of the prune and cherry_pick attributes.
+func validateMergeOpts(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	for _, attr := range []string{"prune", "cherry_pick"} {
+		diags.Append(validateStringList(ctx, config, path.Root(attr), "Invalid Key Path", func(v string) error {
+			return merger.ValidateKeyPaths([]string{v})
+		})...)
+	}
+	return diags
+}
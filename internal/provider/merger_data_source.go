@@ -1,19 +1,18 @@
 package provider
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"github.com/gookit/goutil/maputil"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/k0kubun/pp"
 	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
 	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/finder"
 	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
-	"gopkg.in/yaml.v3"
-	"io"
 	"os"
-	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -23,6 +22,7 @@ import (
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ datasource.DataSource = &MergerDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &MergerDataSource{}
 
 func NewMergerDataSource() datasource.DataSource {
 	return &MergerDataSource{}
@@ -30,15 +30,104 @@ func NewMergerDataSource() datasource.DataSource {
 
 // MergerDataSource defines the data source implementation.
 type MergerDataSource struct {
-	projectConfig string
-	configGlobs   []string
+	projectConfigs      []string
+	rootDir             string
+	baseDir             string
+	factsFile           string
+	envFacts            []EnvFactModel
+	envFactsKey         string
+	derivedFacts        []envfacts.DerivedFact
+	allowedValues       map[string]envfacts.AllowedValues
+	configGlobs         []string
+	configGlobsCompat   bool
+	configGlobsPriority map[string]int
+	numericPrefixOrder  bool
+	mergeOpts           merger.MergeOpts
+	sensitivePaths      []string
 }
 
 // MergerDataSourceModel describes the data source data model.
 type MergerDataSourceModel struct {
-	Id         types.String `tfsdk:"id"`
-	ConfigPath types.String `tfsdk:"config_path"`
-	Result     types.String `tfsdk:"result"`
+	Id                   types.String           `tfsdk:"id"`
+	ContentHash          types.String           `tfsdk:"content_hash"`
+	ConfigPath           types.String           `tfsdk:"config_path"`
+	ResolvedConfigPath   types.String           `tfsdk:"resolved_config_path"`
+	BaseDir              types.String           `tfsdk:"base_dir"`
+	ProjectConfig        types.String           `tfsdk:"project_config"`
+	ProjectConfigs       []types.String         `tfsdk:"project_configs"`
+	MatchedProjectConfig types.String           `tfsdk:"matched_project_config"`
+	RootDir              types.String           `tfsdk:"root_dir"`
+	FactsFile            types.String           `tfsdk:"facts_file"`
+	ExtraFacts           types.Dynamic          `tfsdk:"extra_facts"`
+	Overrides            types.Dynamic          `tfsdk:"overrides"`
+	OverridesPriority    types.String           `tfsdk:"overrides_priority"`
+	ConfigGlobs          []types.String         `tfsdk:"config_globs"`
+	ConfigGlobsCompat    types.Bool             `tfsdk:"config_globs_compat"`
+	ConfigGlobsPriority  map[string]types.Int64 `tfsdk:"config_globs_priority"`
+	NumericPrefixOrder   types.Bool             `tfsdk:"numeric_prefix_order"`
+	Result               types.String           `tfsdk:"result"`
+	ResultObject         types.Dynamic          `tfsdk:"result_object"`
+	ResultJson           types.String           `tfsdk:"result_json"`
+	OutputFormat         types.String           `tfsdk:"output_format"`
+
+	SensitivePaths        []types.String `tfsdk:"sensitive_paths"`
+	SensitiveResult       types.String   `tfsdk:"sensitive_result"`
+	SensitiveContentHash  types.String   `tfsdk:"sensitive_content_hash"`
+	SensitiveResultObject types.Dynamic  `tfsdk:"sensitive_result_object"`
+
+	Sources       types.Map `tfsdk:"sources"`
+	SourceDetails types.Map `tfsdk:"source_details"`
+
+	SkipEval       types.Bool     `tfsdk:"skip_eval"`
+	Prune          []types.String `tfsdk:"prune"`
+	CherryPick     []types.String `tfsdk:"cherry_pick"`
+	FallbackAppend types.Bool     `tfsdk:"fallback_append"`
+	GoPatch        types.Bool     `tfsdk:"go_patch"`
+	MultiDoc       types.Bool     `tfsdk:"multi_doc"`
+}
+
+// SourceModel describes the source of a value in the merged result.
+type SourceModel struct {
+	File     types.String `tfsdk:"file"`
+	Level    types.String `tfsdk:"level"`
+	Operator types.Bool   `tfsdk:"operator"`
+}
+
+var sourceModelAttrTypes = map[string]attr.Type{
+	"file":     types.StringType,
+	"level":    types.StringType,
+	"operator": types.BoolType,
+}
+
+// sourcesValues converts the sources of the merged values to the `sources` and `source_details` attributes.
+func sourcesValues(ctx context.Context, sources merger.Sources) (files types.Map, details types.Map, diags diag.Diagnostics) {
+	fileMap := make(map[string]string, len(sources))
+	detailMap := make(map[string]SourceModel, len(sources))
+	for p, source := range sources {
+		fileMap[p] = source.File
+		detailMap[p] = SourceModel{
+			File:     types.StringValue(source.File),
+			Level:    types.StringValue(source.Level),
+			Operator: types.BoolValue(source.Operator),
+		}
+	}
+	files, d := types.MapValueFrom(ctx, types.StringType, fileMap)
+	diags.Append(d...)
+	details, d = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: sourceModelAttrTypes}, detailMap)
+	diags.Append(d...)
+	return files, details, diags
+}
+
+// MergeOpts returns the merge options of the data source, falling back to base for the ones that are not set.
+func (m MergerDataSourceModel) MergeOpts(base merger.MergeOpts) merger.MergeOpts {
+	return mergeOptsModel{
+		SkipEval:       m.SkipEval,
+		Prune:          m.Prune,
+		CherryPick:     m.CherryPick,
+		FallbackAppend: m.FallbackAppend,
+		GoPatch:        m.GoPatch,
+		MultiDoc:       m.MultiDoc,
+	}.apply(base)
 }
 
 func (d *MergerDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -52,23 +141,207 @@ func (d *MergerDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 		Attributes: map[string]schema.Attribute{
 			// https://github.com/hashicorp/terraform-plugin-testing/issues/84
 			"id": schema.StringAttribute{
-				MarkdownDescription: "Example identifier",
+				MarkdownDescription: "SHA-256 of the merged file paths and contents, the facts and the merge options, in merge order.",
 				Computed:            true,
 			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 of `result_json`, so of the values that are not sensitive, in canonical JSON. " +
+					"Changes only when they change, whatever the `output_format`. Sensitive values are hashed in `sensitive_content_hash`.",
+				Computed: true,
+			},
 			"config_path": schema.StringAttribute{
 				MarkdownDescription: "Path to the most specific configuration file",
 				Required:            true,
 			},
+			"resolved_config_path": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of `config_path`, once resolved against `base_dir`.",
+				Computed:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: baseDirDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"project_config": schema.StringAttribute{
+				MarkdownDescription: "Project Configuration. Overrides the provider setting.",
+				Optional:            true,
+			},
+			"project_configs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: projectConfigsDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"matched_project_config": schema.StringAttribute{
+				MarkdownDescription: "The project structure `config_path` matched, one of `project_config` or `project_configs`.",
+				Computed:            true,
+			},
+			"root_dir": schema.StringAttribute{
+				MarkdownDescription: rootDirDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"facts_file": schema.StringAttribute{
+				MarkdownDescription: factsFileDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"extra_facts": schema.DynamicAttribute{
+				MarkdownDescription: "Facts added to the facts discovered from the path, as an object indexed by the dotted fact name " +
+					"(for example `{ \"facts.workspace\" = terraform.workspace }`), or nested (`{ facts = { workspace = terraform.workspace } }`). " +
+					"Values can be of any type, objects being merged key by key into the facts already set. " +
+					"They can not replace a fact discovered from the path, and can be used by the derived facts of the provider.",
+				Optional: true,
+			},
+			"overrides": schema.DynamicAttribute{
+				MarkdownDescription: "Object merged along with the config files, as a layer of its own set by `overrides_priority`. " +
+					"Spruce operators are evaluated as in config files.",
+				Optional: true,
+			},
+			"overrides_priority": schema.StringAttribute{
+				MarkdownDescription: "Where `overrides` are merged: `highest` to merge them last, overriding the config files and " +
+					"the facts, or `lowest` to merge them first, as defaults the config files can override. Defaults to `highest`.",
+				Optional: true,
+			},
+			"config_globs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: configGlobsDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"config_globs_compat": schema.BoolAttribute{
+				MarkdownDescription: configGlobsCompatDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"config_globs_priority": schema.MapAttribute{
+				ElementType:         types.Int64Type,
+				MarkdownDescription: configGlobsPriorityDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"numeric_prefix_order": schema.BoolAttribute{
+				MarkdownDescription: numericPrefixOrderDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
 			"result": schema.StringAttribute{
 				MarkdownDescription: "Path to the most specific configuration file",
 				Required:            false,
 				Optional:            false,
 				Computed:            true,
 			},
+			"result_json": schema.StringAttribute{
+				MarkdownDescription: "Merged configuration in canonical json format: sorted keys, no insignificant whitespace.",
+				Computed:            true,
+			},
+			"output_format": schema.StringAttribute{
+				MarkdownDescription: "Format of `result` and `sensitive_result`: `yaml`, `json` (indented) or `canonical-json` " +
+					"(sorted keys, no insignificant whitespace). Defaults to `yaml`.",
+				Optional: true,
+			},
+			"result_object": schema.DynamicAttribute{
+				MarkdownDescription: "Merged configuration as a Terraform object, keeping the type of every value. " +
+					"Non string keys are converted to strings.",
+				Computed: true,
+			},
+			"sensitive_paths": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: sensitivePathsDescription + " Used in addition to the provider `sensitive_paths`.",
+				Optional:            true,
+			},
+			"sensitive_result": schema.StringAttribute{
+				MarkdownDescription: "Sensitive values of the merged configuration, encoded as set by `output_format`. Sensitive list elements keep their index, " +
+					"the other elements being `null`. Null when there are no sensitive values.",
+				Computed:  true,
+				Sensitive: true,
+			},
+			"sensitive_content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 of the sensitive values in canonical JSON, as `content_hash`. " +
+					"Changes only when the sensitive values change, whatever the `output_format`. Null when there are no sensitive values.",
+				Computed:  true,
+				Sensitive: true,
+			},
+			"sensitive_result_object": schema.DynamicAttribute{
+				MarkdownDescription: "Same as `sensitive_result`, as a Terraform object.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"sources": schema.MapAttribute{
+				ElementType: types.StringType,
+				MarkdownDescription: "The file that set every value in the merged result, indexed by the dot separated path of the value. " +
+					"List elements are referenced by their index (for example `list.0.name`).",
+				Computed: true,
+			},
+			"source_details": schema.MapNestedAttribute{
+				MarkdownDescription: "Same as `sources`, also including the hierarchy level of the file and whether a spruce operator computed the value.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"file": schema.StringAttribute{
+							MarkdownDescription: "Path of the file that last set the value.",
+							Computed:            true,
+						},
+						"level": schema.StringAttribute{
+							MarkdownDescription: "Hierarchy level of the file: the root directory name or `fact=value`.",
+							Computed:            true,
+						},
+						"operator": schema.BoolAttribute{
+							MarkdownDescription: "Whether the value was computed by a spruce operator defined in the file.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"skip_eval": schema.BoolAttribute{
+				MarkdownDescription: skipEvalDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"prune": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: pruneDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"cherry_pick": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: cherryPickDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"fallback_append": schema.BoolAttribute{
+				MarkdownDescription: fallbackAppendDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"go_patch": schema.BoolAttribute{
+				MarkdownDescription: goPatchDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
+			"multi_doc": schema.BoolAttribute{
+				MarkdownDescription: multiDocDescription + " Overrides the provider setting.",
+				Optional:            true,
+			},
 		},
 	}
 }
 
+func (d *MergerDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateMergeOpts(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateSensitivePaths(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateProjectConfig(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateFactsFile(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateConfigGlobsPriority(ctx, req.Config)...)
+
+	var overridesPriority types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("overrides_priority"), &overridesPriority)...)
+	if !overridesPriority.IsNull() && !overridesPriority.IsUnknown() {
+		switch overridesPriority.ValueString() {
+		case overridesPriorityHighest, overridesPriorityLowest:
+		default:
+			resp.Diagnostics.AddAttributeError(path.Root("overrides_priority"), "Invalid Overrides Priority",
+				fmt.Sprintf("overrides_priority needs to be %q or %q, got %q", overridesPriorityHighest, overridesPriorityLowest, overridesPriority.ValueString()))
+		}
+	}
+
+	var outputFormat types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("output_format"), &outputFormat)...)
+	if !outputFormat.IsNull() && !outputFormat.IsUnknown() {
+		if err := merger.ValidateOutputFormat(outputFormat.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("output_format"), "Invalid Output Format", err.Error())
+		}
+	}
+}
+
 type C struct {
 	ProjectConfig basetypes.StringValue
 	ConfigGlobs   []basetypes.StringValue
@@ -92,11 +365,26 @@ func (d *MergerDataSource) Configure(ctx context.Context, req datasource.Configu
 		return
 	}
 
-	d.projectConfig = providerConfig.ProjectConfig.ValueString()
+	d.projectConfigs = projectConfigList(providerConfig.ProjectConfig, providerConfig.ProjectConfigs)
+	d.rootDir = providerConfig.RootDir.ValueString()
+	d.baseDir = providerConfig.BaseDir.ValueString()
+	d.factsFile = providerConfig.FactsFile.ValueString()
+	d.envFacts = providerConfig.EnvFacts
+	d.envFactsKey = providerConfig.EnvFactsKey.ValueString()
+	var diags diag.Diagnostics
+	d.derivedFacts, diags = derivedFacts(ctx, providerConfig.DerivedFacts)
+	resp.Diagnostics.Append(diags...)
+	d.allowedValues, diags = allowedValues(ctx, providerConfig.AllowedValues)
+	resp.Diagnostics.Append(diags...)
 	d.configGlobs = make([]string, len(providerConfig.ConfigGlobs))
 	for i, v := range providerConfig.ConfigGlobs {
 		d.configGlobs[i] = v.ValueString()
 	}
+	d.configGlobsCompat = providerConfig.ConfigGlobsCompat.ValueBool()
+	d.configGlobsPriority = globPriorities(providerConfig.ConfigGlobsPriority)
+	d.numericPrefixOrder = providerConfig.NumericPrefixOrder.ValueBool()
+	d.mergeOpts = providerConfig.MergeOpts()
+	d.sensitivePaths = stringValues(providerConfig.SensitivePaths)
 	tflog.Trace(ctx, pp.Sprintln(d.configGlobs))
 }
 
@@ -121,74 +409,188 @@ func (d *MergerDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	// For the purposes of this example code, hardcoding a response value to
 	// save into the Terraform state.
 
-	p, err := envfacts.ParseProjectStructure(d.projectConfig)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable parse project, got error: %s", err))
-		return
-
+	projectConfigs := d.projectConfigs
+	if list := projectConfigList(data.ProjectConfig, data.ProjectConfigs); list != nil {
+		projectConfigs = list
 	}
-
-	err = p.MapPathToProject(data.ConfigPath.ValueString(), os.UserHomeDir)
+	if len(projectConfigs) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("project_config"),
+			"Missing Project Configuration",
+			"project_config or project_configs needs to be set either on the provider or on the data source.",
+		)
+	}
+	baseDir := d.baseDir
+	if !data.BaseDir.IsNull() {
+		baseDir = data.BaseDir.ValueString()
+	}
+	configPath, err := envfacts.ResolvePath(baseDir, data.ConfigPath.ValueString(), os.UserHomeDir)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable parse config dir, got error: %s", err))
-		return
+		resp.Diagnostics.AddAttributeError(path.Root("config_path"), "Client Error", fmt.Sprintf("Unable to resolve the config path, got error: %s", err))
 	}
-
-	tflog.Trace(ctx, pp.Sprintln(p))
-	outMap := make(map[string]interface{}, 0)
-	tflog.Trace(ctx, pp.Sprintln(outMap))
-	for _, v := range p.Vars {
-		pathKeys := strings.TrimPrefix(v.VariableName, ".")
-		err := maputil.SetByPath(&outMap, pathKeys, v.VariableValue)
+	rootDir := d.rootDir
+	if !data.RootDir.IsNull() {
+		rootDir = data.RootDir.ValueString()
+	}
+	if rootDir != "" {
+		rootDir, err = envfacts.ResolvePath(baseDir, rootDir, os.UserHomeDir)
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to add key( %s ): %q", v.VariableName, err))
-			return
+			resp.Diagnostics.AddAttributeError(path.Root("root_dir"), "Client Error", fmt.Sprintf("Unable to resolve the root directory, got error: %s", err))
 		}
 	}
-	tflog.Trace(ctx, pp.Sprintln(outMap))
-	out, err := yaml.Marshal(&outMap)
+	factsFile := d.factsFile
+	if !data.FactsFile.IsNull() {
+		factsFile = data.FactsFile.ValueString()
+	}
+	configGlobs := d.configGlobs
+	if data.ConfigGlobs != nil {
+		configGlobs = stringValues(data.ConfigGlobs)
+	}
+	if len(configGlobs) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("config_globs"),
+			"Missing Config Globs",
+			"config_globs needs to be set either on the provider or on the data source.",
+		)
+	}
+	configGlobsCompat := d.configGlobsCompat
+	if !data.ConfigGlobsCompat.IsNull() {
+		configGlobsCompat = data.ConfigGlobsCompat.ValueBool()
+	}
+	configGlobsPriority := d.configGlobsPriority
+	if data.ConfigGlobsPriority != nil {
+		configGlobsPriority = globPriorities(data.ConfigGlobsPriority)
+		resp.Diagnostics.Append(unknownGlobPriorities(configGlobs, configGlobsPriority)...)
+	}
+	numericPrefixOrder := d.numericPrefixOrder
+	if !data.NumericPrefixOrder.IsNull() {
+		numericPrefixOrder = data.NumericPrefixOrder.ValueBool()
+	}
+	resp.Diagnostics.Append(configGlobsDiagnostics(configGlobs, configGlobsCompat)...)
+	extraFacts, err := dynamicObject(ctx, data.ExtraFacts)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("extra_facts"), "Invalid Extra Facts", err.Error())
+	}
+	overrides, err := dynamicObject(ctx, data.Overrides)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable Marshal output, got error: %s", err))
+		resp.Diagnostics.AddAttributeError(path.Root("overrides"), "Invalid Overrides", err.Error())
+	}
+	env, err := envFacts(d.envFacts, d.envFactsKey, os.LookupEnv)
+	if err != nil {
+		resp.Diagnostics.AddError("Missing Environment Variable", err.Error())
+	}
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	var extraFactsByName map[string]interface{}
+	if extraFacts != nil {
+		extraFactsByName = make(map[string]interface{}, len(extraFacts))
+		for name, value := range extraFacts {
+			extraFactsByName[name.(string)] = value
+		}
+	}
 
-	mergeFileNames, err := finder.FindConfigFiles(p, d.configGlobs)
+	result, err := runMerge(ctx, mergeRequest{
+		configPath:        configPath,
+		projectConfigs:    projectConfigs,
+		rootDir:           rootDir,
+		allowedValues:     d.allowedValues,
+		derivedFacts:      d.derivedFacts,
+		factsFile:         factsFile,
+		envFacts:          env,
+		extraFacts:        extraFactsByName,
+		overrides:         overrides,
+		overridesPriority: data.OverridesPriority.ValueString(),
+		configGlobs:       configGlobs,
+		findOpts: finder.Options{
+			Compat:        configGlobsCompat,
+			Priorities:    configGlobsPriority,
+			NumericPrefix: numericPrefixOrder,
+		},
+		mergeOpts: data.MergeOpts(d.mergeOpts),
+	})
+	if errors.Is(err, envfacts.ErrAmbiguousRoot) {
+		resp.Diagnostics.AddAttributeError(path.Root("config_path"), "Ambiguous Project Root", err.Error())
+		return
+	}
+	if notAllowed := notAllowedErrors(err); len(notAllowed) > 0 {
+		for _, e := range notAllowed {
+			resp.Diagnostics.AddAttributeError(path.Root("config_path"), "Invalid Fact Value", e.Error())
+		}
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable FindConfigFiles, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error", err.Error())
 		return
 	}
-	yamlFiles := make([]merger.YamlFile, 0)
+	ev, sources := result.evaluator, result.sources
 
-	for _, filePath := range mergeFileNames {
-		y, err := merger.LoadYamlFile(filePath)
+	outputFormat := merger.FormatYAML
+	if !data.OutputFormat.IsNull() {
+		outputFormat = data.OutputFormat.ValueString()
+	}
+
+	sensitivePaths := append(d.sensitivePaths[:len(d.sensitivePaths):len(d.sensitivePaths)], stringValues(data.SensitivePaths)...)
+	public, sensitive := merger.SplitSensitive(ev.Tree, sensitivePaths, sources)
+
+	data.SensitiveResult = types.StringNull()
+	data.SensitiveResultObject = types.DynamicNull()
+	data.SensitiveContentHash = types.StringNull()
+	if sensitive != nil {
+		ctx = tflog.MaskLogStrings(ctx, merger.SensitiveStrings(sensitive)...)
+
+		sensitiveEncoded, err := merger.Encode(sensitive, outputFormat)
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to LoadYamlFile, got error: %s", err))
+			resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to encode sensitive result, got error: %s", err))
 			return
 		}
-
-		yamlFiles = append(yamlFiles, y)
+		sensitiveObject, err := toTerraformValue(ctx, sensitive)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to convert sensitive result to an object, got error: %s", err))
+			return
+		}
+		data.SensitiveResult = types.StringValue(string(sensitiveEncoded))
+		sensitiveJson, err := merger.Encode(sensitive, merger.FormatCanonicalJSON)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to encode sensitive result, got error: %s", err))
+			return
+		}
+		data.SensitiveContentHash = types.StringValue(merger.ContentHash(sensitiveJson))
+		data.SensitiveResultObject = types.DynamicValue(sensitiveObject)
 	}
 
-	yamlFiles = append(yamlFiles, merger.YamlFile{
-		Path:   "facts.yaml",
-		Reader: io.NopCloser(bytes.NewReader(out)),
-	})
-
-	ev, err := merger.MergeAllDocs(yamlFiles, merger.MergeOpts{})
+	merged, err := merger.Encode(public, outputFormat)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable merger.MergeAllDocs, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to encode result, got error: %s", err))
 		return
 	}
-	merged, err := yaml.Marshal(ev.Tree)
+	mergedJson, err := merger.Encode(public, merger.FormatCanonicalJSON)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable yaml.Marshal, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to encode result, got error: %s", err))
+		return
+	}
+	tflog.Trace(ctx, "merged result", map[string]interface{}{"result": string(merged)})
+
+	resultObject, err := toTerraformValue(ctx, public)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error: ", fmt.Sprintf("Unable to convert result to an object, got error: %s", err))
 		return
 	}
 
 	data.Result = types.StringValue(string(merged))
+	data.ResultJson = types.StringValue(string(mergedJson))
+	data.ResultObject = types.DynamicValue(resultObject)
+	var diags diag.Diagnostics
+	data.Sources, data.SourceDetails, diags = sourcesValues(ctx, sources)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ContentHash = types.StringValue(merger.ContentHash(mergedJson))
 	// https://developer.hashicorp.com/terraform/plugin/framework/acctests#implement-id-attribute
-	// We also need to set this (should be a hash)
-	data.Id = types.StringValue(string(out))
+	data.Id = types.StringValue(result.fingerprint)
+	data.MatchedProjectConfig = types.StringValue(result.projectConfig)
+	data.ResolvedConfigPath = types.StringValue(configPath)
 
 	// Write logs using the tflog package
 	// Documentation: https://terraform.io/plugin/log
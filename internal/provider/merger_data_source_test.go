@@ -1,9 +1,20 @@
 package provider
 
 import (
+	"context"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccExampleDataSource(t *testing.T) {
@@ -17,7 +28,26 @@ func TestAccExampleDataSource(t *testing.T) {
 				Config: testAccExampleDataSourceConfig,
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("data.config-merger_result.test", "result", testAccExampleDataSourceResult),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_json", testAccExampleDataSourceResultJson),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "id", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "content_hash", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "sources.root_key.key_1", regexp.MustCompile(`tests/config/production/us-west-2/s3bucket/config.yaml$`)),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "sources.root_key.key_2", regexp.MustCompile(`tests/config/production/us-west-2/s3bucket/config.yaml$`)),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "sources.facts.environment", "facts.yaml"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "source_details.root_key.key_2.level", "facts.project=s3bucket"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "source_details.root_key.key_2.operator", "true"),
 				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.config-merger_result.test",
+						tfjsonpath.New("result_object").AtMapKey("root_key"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"key_1": knownvalue.StringExact("s3bucket_value_1"),
+							"key_2": knownvalue.StringExact("production-s3bucket_value_2"),
+							"key_3": knownvalue.StringExact("s3bucket_value_1"),
+						}),
+					),
+				},
 			},
 		},
 	})
@@ -37,6 +67,9 @@ data "config-merger_result" "test" {
 }
 `
 
+const testAccExampleDataSourceResultJson = `{"facts":{"environment":"production","project":"s3bucket","region":"us-west-2"},` +
+	`"root_key":{"key_1":"s3bucket_value_1","key_2":"production-s3bucket_value_2","key_3":"s3bucket_value_1"}}`
+
 const testAccExampleDataSourceResult = `facts:
     environment: production
     project: s3bucket
@@ -46,3 +79,724 @@ root_key:
     key_2: production-s3bucket_value_2
     key_3: s3bucket_value_1
 `
+
+func TestAccMergeOptionsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMergeOptionsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.provider_defaults", "result", `root_key:
+    key_1: s3bucket_value_1
+    key_2: production-s3bucket_value_2
+    key_3: s3bucket_value_1
+`),
+					resource.TestCheckResourceAttr("data.config-merger_result.overridden", "result", `root_key:
+    key_2: (( concat .facts.environment "-" .facts.project "_value_2" ))
+`),
+				),
+			},
+		},
+	})
+}
+
+const testAccMergeOptionsDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = [
+    "config.yaml",
+  ]
+  prune = ["facts"]
+}
+
+data "config-merger_result" "provider_defaults" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+
+data "config-merger_result" "overridden" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  skip_eval   = true
+  prune       = []
+  cherry_pick = ["root_key.key_2"]
+}
+`
+
+func TestAccSensitiveDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSensitiveDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result", `facts:
+    project: s3bucket
+    region: us-west-2
+root_key:
+    key_2: production-s3bucket_value_2
+    key_3: s3bucket_value_1
+`),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "sensitive_result", `facts:
+    environment: production
+root_key:
+    key_1: s3bucket_value_1
+`),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "content_hash", "e667ee875707a2946549aba30a0356e4db6205ef72539d0ad0f7e5e7fac7a3e9"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "sensitive_content_hash", "76e3889e5a503aa9ac712f0f19198e5cb96fb7393e09bd1595a1b9772971aff3"),
+					resource.TestCheckResourceAttrPair("data.config-merger_result.test", "content_hash", "data.config-merger_result.json", "content_hash"),
+					resource.TestCheckResourceAttrPair("data.config-merger_result.test", "sensitive_content_hash", "data.config-merger_result.json", "sensitive_content_hash"),
+				),
+			},
+		},
+	})
+}
+
+const testAccSensitiveDataSourceConfig = `
+provider "config-merger" {
+  project_config  = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs    = [
+    "config.yaml",
+  ]
+  sensitive_paths = ["*.environment"]
+}
+
+data "config-merger_result" "test" {
+  config_path     = "../../tests/config/production/us-west-2/s3bucket"
+  sensitive_paths = ["root_key.key_1"]
+}
+
+data "config-merger_result" "json" {
+  config_path     = "../../tests/config/production/us-west-2/s3bucket"
+  sensitive_paths = ["root_key.key_1"]
+  output_format   = "json"
+}
+`
+
+func TestAccOverrideProjectDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOverrideProjectDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result", `meta:
+    environment: production
+    project: s3bucket
+    region: us-west-2
+root_key:
+    key_1: s3bucket_value_1
+    key_2: (( concat .facts.environment "-" .facts.project "_value_2" ))
+    key_3: (( grab root_key.key_1 ))
+`),
+				),
+			},
+		},
+	})
+}
+
+const testAccOverrideProjectDataSourceConfig = `
+provider "config-merger" {}
+
+data "config-merger_result" "test" {
+  config_path    = "../../tests/config/production/us-west-2/s3bucket"
+  project_config = "config/{{meta.environment}}/{{meta.region}}/{{meta.project}}"
+  config_globs   = ["ignored-config.yaml", "config.yaml"]
+  skip_eval      = true
+  cherry_pick    = ["meta", "root_key"]
+}
+`
+
+func TestAccProjectConfigsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProjectConfigsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "matched_project_config", "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.project", "s3bucket"),
+				),
+			},
+		},
+	})
+}
+
+const testAccProjectConfigsDataSourceConfig = `
+provider "config-merger" {
+  project_configs = [
+    "config/{{facts.environment}}/{{facts.project}}",
+    "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}",
+  ]
+  config_globs = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+func TestAccRootDirDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRootDirDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.environment", "production"),
+				),
+			},
+		},
+	})
+}
+
+const testAccRootDirDataSourceConfig = `
+provider "config-merger" {
+  project_config = "hierarchy/{{facts.environment}}/**"
+  root_dir       = "../../tests/config"
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+func TestAccAllowedValuesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAllowedValuesOverrideProjectConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "matched_project_config", "config/{{facts.environment}}/{{facts.region}}"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.environment", "production"),
+				),
+			},
+			{
+				Config:      testAccAllowedValuesOverrideNotAllowedConfig,
+				ExpectError: regexp.MustCompile(`valid choices are "staging"`),
+			},
+			{
+				Config:      testAccAllowedValuesUnknownVariableConfig,
+				ExpectError: regexp.MustCompile(`Unknown Allowed Values`),
+			},
+		},
+	})
+}
+
+const testAccAllowedValuesOverrideProjectConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  allowed_values = {
+    "facts.environment" = { values = ["production"] }
+    "facts.project"     = { values = ["s3bucket"] }
+  }
+}
+
+data "config-merger_result" "test" {
+  config_path    = "../../tests/config/production/us-west-2"
+  project_config = "config/{{facts.environment}}/{{facts.region}}"
+}
+`
+
+const testAccAllowedValuesOverrideNotAllowedConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  allowed_values = {
+    "facts.environment" = { values = ["staging"] }
+    "facts.project"     = { values = ["s3bucket"] }
+  }
+}
+
+data "config-merger_result" "test" {
+  config_path    = "../../tests/config/production/us-west-2"
+  project_config = "config/{{facts.environment}}/{{facts.region}}"
+}
+`
+
+const testAccAllowedValuesUnknownVariableConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  allowed_values = {
+    "facts.app" = { values = ["app"] }
+  }
+}
+
+data "config-merger_result" "test" {
+  config_path    = "../../tests/config/production/us-west-2"
+  project_config = "config/{{facts.environment}}/{{facts.region}}"
+}
+`
+
+func TestAccDerivedFactsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDerivedFactsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.region_short", "usw2"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.stack", "production-usw2"),
+				),
+			},
+			{
+				Config:      testAccInvalidDerivedFactsDataSourceConfig,
+				ExpectError: regexp.MustCompile(`Invalid Derived Fact`),
+			},
+			{
+				Config: testAccDerivedFactsOverrideProjectConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.region_short", "usw2"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.cost_center", "shared"),
+					resource.TestCheckNoResourceAttr("data.config-merger_result.test", "result_object.facts.stack"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDerivedFactsDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  derived_facts = [
+    {
+      name   = "facts.region_short"
+      fact   = "facts.region"
+      lookup = { "us-west-2" = "usw2" }
+    },
+    {
+      name     = "facts.stack"
+      template = "{{facts.environment}}-{{facts.region_short}}"
+    },
+  ]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+const testAccInvalidDerivedFactsDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  derived_facts = [
+    {
+      name = "facts.region_short"
+      fact = "facts.region"
+    },
+  ]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+const testAccDerivedFactsOverrideProjectConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  derived_facts = [
+    {
+      name   = "facts.region_short"
+      fact   = "facts.region"
+      lookup = { "us-west-2" = "usw2" }
+    },
+    {
+      name    = "facts.cost_center"
+      fact    = "facts.project"
+      lookup  = { "s3bucket" = "storage" }
+      default = "shared"
+    },
+    {
+      name     = "facts.stack"
+      template = "{{facts.project}}-{{facts.region_short}}"
+    },
+  ]
+}
+
+data "config-merger_result" "test" {
+  config_path    = "../../tests/config/production/us-west-2"
+  project_config = "config/{{facts.environment}}/{{facts.region}}"
+}
+`
+
+func TestAccFactsFileDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFactsFileDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.account_id", "123456789012"),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "sources.facts.account_id", regexp.MustCompile(`tests/config/production/_facts.yaml$`)),
+					resource.TestCheckNoResourceAttr("data.config-merger_result.test", "result_object.account_id"),
+				),
+			},
+			{
+				Config:      testAccInvalidFactsFileDataSourceConfig,
+				ExpectError: regexp.MustCompile(`Invalid Facts File`),
+			},
+		},
+	})
+}
+
+const testAccFactsFileDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["*.yaml"]
+  facts_file     = "_facts.yaml"
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  cherry_pick = ["facts"]
+}
+`
+
+const testAccInvalidFactsFileDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  facts_file  = "production/_facts.yaml"
+}
+`
+
+func TestAccOverridesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOverridesDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.highest", "result_object.facts.workspace", "blue"),
+					resource.TestCheckResourceAttr("data.config-merger_result.highest", "result_object.root_key.key_1", "blue"),
+					resource.TestCheckResourceAttr("data.config-merger_result.highest", "sources.root_key.key_1", "overrides.yaml"),
+					resource.TestCheckResourceAttr("data.config-merger_result.lowest", "result_object.root_key.key_1", "s3bucket_value_1"),
+					resource.TestCheckResourceAttr("data.config-merger_result.lowest", "result_object.root_key.key_4", "blue"),
+				),
+			},
+			{
+				Config: testAccNestedExtraFactsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.workspace", "blue"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.project", "s3bucket"),
+				),
+			},
+			{
+				Config:      testAccExtraFactsAlreadySetDataSourceConfig,
+				ExpectError: regexp.MustCompile(`extra fact "facts.project" is already set`),
+			},
+			{
+				Config:      testAccInvalidOverridesDataSourceConfig,
+				ExpectError: regexp.MustCompile(`Invalid Overrides`),
+			},
+		},
+	})
+}
+
+const testAccOverridesDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "highest" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  extra_facts = {
+    "facts.workspace" = "blue"
+  }
+  overrides = {
+    root_key = {
+      key_1 = "(( grab facts.workspace ))"
+    }
+  }
+}
+
+data "config-merger_result" "lowest" {
+  config_path        = "../../tests/config/production/us-west-2/s3bucket"
+  extra_facts        = { "facts.workspace" = "blue" }
+  overrides_priority = "lowest"
+  overrides = {
+    root_key = {
+      key_1 = "ignored"
+      key_4 = "(( grab facts.workspace ))"
+    }
+  }
+}
+`
+
+const testAccNestedExtraFactsDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  extra_facts = {
+    facts = { workspace = "blue" }
+  }
+}
+`
+
+const testAccExtraFactsAlreadySetDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  extra_facts = {
+    facts = { project = "dns" }
+  }
+}
+`
+
+const testAccInvalidOverridesDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+  overrides   = ["not", "an", "object"]
+}
+`
+
+func TestAccEnvFactsDataSource(t *testing.T) {
+	t.Setenv("CONFIG_MERGER_TEST_BUILD", "42")
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccEnvFactsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.ci.CONFIG_MERGER_TEST_BUILD", "42"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.ci.CONFIG_MERGER_TEST_RING", "ga"),
+					resource.TestCheckNoResourceAttr("data.config-merger_result.test", "result_object.ci.CONFIG_MERGER_TEST_UNSET"),
+				),
+			},
+		},
+	})
+}
+
+const testAccEnvFactsDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml"]
+  env_facts_key  = "ci"
+  env_facts = [
+    { name = "CONFIG_MERGER_TEST_BUILD", required = true },
+    { name = "CONFIG_MERGER_TEST_RING", default = "ga" },
+    { name = "CONFIG_MERGER_TEST_UNSET" },
+  ]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+func TestAccBaseDirDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBaseDirDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.facts.project", "s3bucket"),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "resolved_config_path", regexp.MustCompile(`^/.*/tests/config/production/us-west-2/s3bucket$`)),
+				),
+			},
+		},
+	})
+}
+
+const testAccBaseDirDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  base_dir       = "../.."
+  config_globs   = ["config.yaml"]
+}
+
+data "config-merger_result" "test" {
+  base_dir    = "../../tests"
+  config_path = "config/production/us-west-2/s3bucket"
+}
+`
+
+func TestAccConfigGlobsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigGlobsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.root_key.key_1", "s3bucket_value_1"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.root_key.key_4", "conf_d_value_4"),
+					resource.TestCheckNoResourceAttr("data.config-merger_result.test", "result_object.ignored_key"),
+					resource.TestCheckNoResourceAttr("data.config-merger_result.compat", "result_object.root_key.key_4"),
+					resource.TestCheckResourceAttr("data.config-merger_result.compat", "result_object.ignored_key", "ignored_value"),
+				),
+			},
+		},
+	})
+}
+
+const testAccConfigGlobsDataSourceConfig = `
+provider "config-merger" {
+  project_config = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs   = ["config.yaml", "conf.d/*.yaml"]
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+
+data "config-merger_result" "compat" {
+  config_path         = "../../tests/config/production/us-west-2/s3bucket"
+  config_globs        = ["config.yaml", "conf.d/*.yaml"]
+  config_globs_compat = true
+}
+`
+
+// TestMergerDataSourceConfigGlobsCompat reads the data source directly, as acceptance tests can not check warnings.
+func TestMergerDataSourceConfigGlobsCompat(t *testing.T) {
+	ctx := context.Background()
+	d := &MergerDataSource{}
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	values["config_path"] = tftypes.NewValue(tftypes.String, "../../tests/config/production/us-west-2/s3bucket")
+	values["project_config"] = tftypes.NewValue(tftypes.String, "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}")
+	values["config_globs"] = tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{
+		tftypes.NewValue(tftypes.String, "config.yaml"),
+		tftypes.NewValue(tftypes.String, "conf.d/*.yaml"),
+	})
+	values["config_globs_compat"] = tftypes.NewValue(tftypes.Bool, true)
+
+	req := datasource.ReadRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)}}
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+	d.Read(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read() errors = %v", resp.Diagnostics.Errors())
+	}
+	warnings := resp.Diagnostics.Warnings()
+	if len(warnings) != 1 || warnings[0].Summary() != "Ignored Config Glob Components" {
+		t.Errorf("Read() warnings = %v, want one Ignored Config Glob Components warning", warnings)
+	}
+	var result types.String
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("result"), &result)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("GetAttribute() errors = %v", resp.Diagnostics.Errors())
+	}
+	if !strings.Contains(result.ValueString(), "ignored_key") || strings.Contains(result.ValueString(), "key_4") {
+		t.Errorf("Read() result = %q, want ignored_key without key_4", result.ValueString())
+	}
+}
+
+func TestAccConfigFilesOrderDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfigFilesOrderDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.root_key.key_1", "s3bucket_value_1"),
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.ignored_key", "ignored_value"),
+					resource.TestMatchResourceAttr("data.config-merger_result.test", "sources.root_key.key_1", regexp.MustCompile(`/s3bucket/config\.yaml$`)),
+				),
+			},
+			{
+				Config:      testAccConfigFilesOrderUnknownGlobConfig,
+				ExpectError: regexp.MustCompile(`Unknown Config Glob`),
+			},
+			{
+				Config: testAccConfigFilesOrderInheritedPriorityConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.config-merger_result.test", "result_object.root_key.key_1", "s3bucket_value_1"),
+				),
+			},
+		},
+	})
+}
+
+const testAccConfigFilesOrderDataSourceConfig = `
+provider "config-merger" {
+  project_config        = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs          = ["*config.yaml", "config.yaml"]
+  config_globs_priority = { "config.yaml" = 10 }
+  numeric_prefix_order  = true
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+const testAccConfigFilesOrderUnknownGlobConfig = `
+provider "config-merger" {
+  project_config        = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs          = ["config.yaml"]
+  config_globs_priority = { "*.yaml" = 10 }
+}
+
+data "config-merger_result" "test" {
+  config_path = "../../tests/config/production/us-west-2/s3bucket"
+}
+`
+
+const testAccConfigFilesOrderInheritedPriorityConfig = `
+provider "config-merger" {
+  project_config        = "config/{{facts.environment}}/{{facts.region}}/{{facts.project}}"
+  config_globs          = ["config.yaml", "*.override.yaml"]
+  config_globs_priority = { "*.override.yaml" = 10 }
+}
+
+data "config-merger_result" "test" {
+  config_path  = "../../tests/config/production/us-west-2/s3bucket"
+  config_globs = ["config.yaml"]
+}
+`
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/geofffranks/spruce"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/k0kubun/pp"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/finder"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
+	"gopkg.in/yaml.v3"
+)
+
+// mergeRequest holds the inputs of the merge of the hierarchy leading to configPath.
+type mergeRequest struct {
+	configPath     string
+	projectConfigs []string
+	rootDir        string
+	allowedValues  map[string]envfacts.AllowedValues
+	derivedFacts   []envfacts.DerivedFact
+	// envFacts are the facts of the environment variables, indexed by their dotted name.
+	envFacts map[string]interface{}
+	// extraFacts are added to the facts discovered from the path, indexed by their dotted name.
+	extraFacts map[string]interface{}
+	// overrides are merged along with the config files, before them when overridesPriority is lowest and after
+	// everything else otherwise.
+	overrides         map[interface{}]interface{}
+	overridesPriority string
+	// factsFile is the name of the facts file of every level, none when empty.
+	factsFile   string
+	configGlobs []string
+	// findOpts control how the config files matching configGlobs are found and ordered.
+	findOpts  finder.Options
+	mergeOpts merger.MergeOpts
+}
+
+// mergeResult holds the outputs of a merge.
+type mergeResult struct {
+	project envfacts.ProjectStructure
+	// projectConfig is the project structure the config path matched.
+	projectConfig string
+	facts         map[string]interface{}
+	evaluator     *spruce.Evaluator
+	sources       merger.Sources
+	fingerprint   string
+}
+
+// mapProject parses the project structures and maps the config path onto the first one it matches, returning the
+// mapped structure along with the project configuration it was parsed from. The root of the structures is rootDir
+// when set, and the values of their variables are restricted to allowed.
+func mapProject(ctx context.Context, configPath string, projectConfigs []string, rootDir string, allowed map[string]envfacts.AllowedValues) (envfacts.ProjectStructure, string, error) {
+	structures, err := parseProjectConfigs(projectConfigs)
+	if err != nil {
+		return envfacts.ProjectStructure{}, "", fmt.Errorf("Unable parse project, got error: %s", err)
+	}
+	if err := setAllowedValues(structures, allowed); err != nil {
+		return envfacts.ProjectStructure{}, "", fmt.Errorf("Unable parse project, got error: %s", err)
+	}
+	for i := range structures {
+		structures[i].RootDir = rootDir
+	}
+
+	p, idx, err := envfacts.MapPathToProjects(structures, configPath, os.UserHomeDir)
+	if err != nil {
+		return p, "", fmt.Errorf("Unable parse config dir, got error: %w", err)
+	}
+	tflog.Trace(ctx, pp.Sprintln(p))
+	return p, projectConfigs[idx], nil
+}
+
+// runMerge finds the config files of every level of the hierarchy leading to the config path, and merges them
+// along with the facts discovered from the path and the facts derived from them.
+func runMerge(ctx context.Context, req mergeRequest) (result mergeResult, err error) {
+	result.project, result.projectConfig, err = mapProject(ctx, req.configPath, req.projectConfigs, req.rootDir, req.allowedValues)
+	if err != nil {
+		return result, err
+	}
+
+	result.facts, err = result.project.Facts()
+	if err != nil {
+		return result, err
+	}
+	if err := envfacts.AddFacts(result.facts, req.envFacts); err != nil {
+		return result, fmt.Errorf("Unable add environment facts, got error: %s", err)
+	}
+	if err := envfacts.AddFacts(result.facts, req.extraFacts); err != nil {
+		return result, fmt.Errorf("Unable add extra facts, got error: %s", err)
+	}
+	if err := envfacts.DeriveFacts(result.facts, req.derivedFacts); err != nil {
+		return result, fmt.Errorf("Unable derive facts, got error: %s", err)
+	}
+	tflog.Trace(ctx, pp.Sprintln(result.facts))
+	out, err := yaml.Marshal(&result.facts)
+	if err != nil {
+		return result, fmt.Errorf("Unable Marshal output, got error: %s", err)
+	}
+
+	mergeFiles, err := finder.FindLevelConfigFiles(result.project, req.configGlobs, req.findOpts)
+	if err != nil {
+		return result, fmt.Errorf("Unable FindConfigFiles, got error: %s", err)
+	}
+	yamlFiles := make([]merger.YamlFile, 0)
+
+	for _, mergeFile := range mergeFiles {
+		if req.factsFile != "" && filepath.Base(mergeFile.Path) == req.factsFile {
+			continue
+		}
+		y, err := merger.LoadYamlFile(mergeFile.Path)
+		if err != nil {
+			return result, fmt.Errorf("Unable to LoadYamlFile, got error: %s", err)
+		}
+		y.Level = mergeFile.Level.Level()
+
+		yamlFiles = append(yamlFiles, y)
+	}
+
+	if req.factsFile != "" {
+		factsFiles, err := finder.FindLevelFactsFiles(result.project, req.factsFile)
+		if err != nil {
+			return result, fmt.Errorf("Unable FindLevelFactsFiles, got error: %s", err)
+		}
+		for _, factsFile := range factsFiles {
+			y, err := loadFactsFile(factsFile.Path)
+			if err != nil {
+				return result, fmt.Errorf("Unable to load facts file, got error: %s", err)
+			}
+			y.Level = factsFile.Level.Level()
+			yamlFiles = append(yamlFiles, y)
+		}
+	}
+
+	yamlFiles = append(yamlFiles, merger.YamlFile{
+		Path:   "facts.yaml",
+		Reader: io.NopCloser(bytes.NewReader(out)),
+		Level:  "facts",
+	})
+
+	if req.overrides != nil {
+		out, err := yaml.Marshal(req.overrides)
+		if err != nil {
+			return result, fmt.Errorf("Unable Marshal overrides, got error: %s", err)
+		}
+		overrides := merger.YamlFile{
+			Path:   "overrides.yaml",
+			Reader: io.NopCloser(bytes.NewReader(out)),
+			Level:  "overrides",
+		}
+		if req.overridesPriority == overridesPriorityLowest {
+			yamlFiles = append([]merger.YamlFile{overrides}, yamlFiles...)
+		} else {
+			yamlFiles = append(yamlFiles, overrides)
+		}
+	}
+
+	result.fingerprint, yamlFiles, err = merger.Fingerprint(yamlFiles, req.mergeOpts)
+	if err != nil {
+		return result, fmt.Errorf("Unable to read config files, got error: %s", err)
+	}
+
+	result.evaluator, result.sources, err = merger.MergeAllDocsWithSources(yamlFiles, req.mergeOpts)
+	if err != nil {
+		return result, fmt.Errorf("Unable merger.MergeAllDocs, got error: %s", err)
+	}
+	return result, nil
+}
+
+const (
+	overridesPriorityHighest = "highest"
+	overridesPriorityLowest  = "lowest"
+)
+
+// factsKey is the key the content of the facts files is merged into.
+const factsKey = "facts"
+
+// loadFactsFile loads a facts file, nesting its content under the facts key.
+func loadFactsFile(file string) (merger.YamlFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return merger.YamlFile{}, err
+	}
+	var content map[string]interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return merger.YamlFile{}, fmt.Errorf("%s: the facts file needs to hold a map: %s", file, err)
+	}
+	if content == nil {
+		// an empty facts file adds no fact, rather than unsetting them
+		content = map[string]interface{}{}
+	}
+	out, err := yaml.Marshal(map[string]interface{}{factsKey: content})
+	if err != nil {
+		return merger.YamlFile{}, err
+	}
+	return merger.YamlFile{Path: file, Reader: io.NopCloser(bytes.NewReader(out))}, nil
+}
@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+)
+
+const projectConfigsDescription = "Project structures tried in order, the first one the config path fully matches being used. " +
+	"Alternative to `project_config` for hierarchies that mix several layouts."
+
+const rootDirDescription = "Directory holding the root of the project structure, `config_path` having to be below it. " +
+	"Its name does not have to match the root of the project structure. When not set, the root is the directory holding " +
+	"a `" + envfacts.RootMarker + "` file, or else the directory named after the root of the project structure."
+
+const baseDirDescription = "Directory relative paths such as `config_path`, `root_path` and `root_dir` are resolved against, " +
+	"instead of the working directory of Terraform, for example `path.root`. Can start with `~` for the home directory."
+
+const factsFileDescription = "Name of the facts file of every level, for example `_facts.yaml`. Its content is merged " +
+	"under the `facts` key, in hierarchy order, after the config files and before the facts discovered from the path, " +
+	"so that lower levels can use the facts of the upper levels. Facts files are never merged as config files. No facts file when not set."
+
+// projectConfigList returns the project structures set by either project_config or project_configs, nil when none
+// of them is set.
+func projectConfigList(projectConfig types.String, projectConfigs []types.String) []string {
+	if !projectConfig.IsNull() {
+		return []string{projectConfig.ValueString()}
+	}
+	if projectConfigs != nil {
+		return stringValues(projectConfigs)
+	}
+	return nil
+}
+
+// parseProjectConfigs parses the project structures, in order.
+func parseProjectConfigs(projectConfigs []string) ([]envfacts.ProjectStructure, error) {
+	structures := make([]envfacts.ProjectStructure, len(projectConfigs))
+	for i, projectConfig := range projectConfigs {
+		p, err := envfacts.ParseProjectStructure(projectConfig)
+		if err != nil {
+			return nil, err
+		}
+		structures[i] = p
+	}
+	return structures, nil
+}
+
+// AllowedValuesModel describes the values a variable of the project structure can take.
+type AllowedValuesModel struct {
+	Values  types.List   `tfsdk:"values"`
+	Pattern types.String `tfsdk:"pattern"`
+}
+
+var allowedValuesAttribute = schema.MapNestedAttribute{
+	MarkdownDescription: "Values the variables of the project structure can take, indexed by the variable name as written " +
+		"in the project structure (for example `facts.environment`). Directories holding other values are reported as errors, " +
+		"listing the valid choices, instead of being merged or listed as leaves. The names have to be variables of `project_config` " +
+		"or `project_configs`; data sources overriding the project structure ignore the entries for variables it does not have.",
+	Optional: true,
+	NestedObject: schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"values": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: "The allowed values.",
+				Optional:            true,
+			},
+			"pattern": schema.StringAttribute{
+				MarkdownDescription: "Regular expression the values have to fully match, instead of `values`.",
+				Optional:            true,
+			},
+		},
+	},
+}
+
+// allowedValues converts the allowed values models.
+func allowedValues(ctx context.Context, models map[string]AllowedValuesModel) (allowed map[string]envfacts.AllowedValues, diags diag.Diagnostics) {
+	if models == nil {
+		return nil, nil
+	}
+	allowed = make(map[string]envfacts.AllowedValues, len(models))
+	for name, m := range models {
+		a := envfacts.AllowedValues{Pattern: m.Pattern.ValueString()}
+		if !m.Values.IsNull() && !m.Values.IsUnknown() {
+			diags.Append(m.Values.ElementsAs(ctx, &a.Values, false)...)
+		}
+		allowed[name] = a
+	}
+	return allowed, diags
+}
+
+// setAllowedValues restricts the values of the variables of the project structures. Entries for variables the
+// structures do not have are ignored, as data sources can override the project structures of the provider.
+func setAllowedValues(structures []envfacts.ProjectStructure, allowed map[string]envfacts.AllowedValues) error {
+	for i := range structures {
+		if err := structures[i].SetAllowedValues(allowed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unknownAllowedValues returns the names of the allowed values entries that are not variables of any of the
+// structures, in order.
+func unknownAllowedValues(structures []envfacts.ProjectStructure, allowed map[string]AllowedValuesModel) []string {
+	names := make(map[string]bool)
+	for _, p := range structures {
+		for _, v := range p.Vars {
+			names[v.VariableName] = true
+		}
+	}
+	unknown := make([]string, 0)
+	for name := range allowed {
+		if !names[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// notAllowedErrors returns the NotAllowedError errors held by err, which can join several of them.
+func notAllowedErrors(err error) []*envfacts.NotAllowedError {
+	errs := make([]*envfacts.NotAllowedError, 0)
+	switch e := err.(type) {
+	case *envfacts.NotAllowedError:
+		return append(errs, e)
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			errs = append(errs, notAllowedErrors(inner)...)
+		}
+	case interface{ Unwrap() error }:
+		return notAllowedErrors(e.Unwrap())
+	}
+	return errs
+}
+
+// validateAllowedValues checks that every allowed values entry holds either a list of values or a valid pattern.
+func validateAllowedValues(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	var m types.Map
+	diags.Append(config.GetAttribute(ctx, path.Root("allowed_values"), &m)...)
+	if diags.HasError() || m.IsNull() || m.IsUnknown() {
+		return diags
+	}
+	models := make(map[string]AllowedValuesModel)
+	diags.Append(m.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	for name, model := range models {
+		if model.Values.IsUnknown() || model.Pattern.IsUnknown() {
+			continue
+		}
+		allowed, d := allowedValues(ctx, map[string]AllowedValuesModel{name: model})
+		diags.Append(d...)
+		if d.HasError() {
+			continue
+		}
+		if err := allowed[name].Validate(); err != nil {
+			diags.AddAttributeError(path.Root("allowed_values").AtMapKey(name), "Invalid Allowed Values", err.Error())
+		}
+	}
+	diags.Append(validateAllowedValuesNames(ctx, config, models)...)
+	return diags
+}
+
+// validateAllowedValuesNames checks that the allowed values entries are variables of the project structures set
+// along with them. Unknown and invalid project structures are skipped as they are validated on their own.
+func validateAllowedValuesNames(ctx context.Context, config tfsdk.Config, models map[string]AllowedValuesModel) (diags diag.Diagnostics) {
+	var projectConfig types.String
+	var projectConfigList types.List
+	diags.Append(config.GetAttribute(ctx, path.Root("project_config"), &projectConfig)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("project_configs"), &projectConfigList)...)
+	if diags.HasError() || projectConfig.IsUnknown() || projectConfigList.IsUnknown() {
+		return diags
+	}
+	projectConfigs := make([]string, 0)
+	if !projectConfig.IsNull() {
+		projectConfigs = append(projectConfigs, projectConfig.ValueString())
+	}
+	for _, elem := range projectConfigList.Elements() {
+		value, ok := elem.(types.String)
+		if !ok || value.IsUnknown() {
+			return diags
+		}
+		projectConfigs = append(projectConfigs, value.ValueString())
+	}
+	if len(projectConfigs) == 0 {
+		return diags
+	}
+	structures, err := parseProjectConfigs(projectConfigs)
+	if err != nil {
+		return diags
+	}
+	for _, name := range unknownAllowedValues(structures, models) {
+		diags.AddAttributeError(path.Root("allowed_values").AtMapKey(name), "Unknown Allowed Values",
+			fmt.Sprintf("allowed values are set for %q, which is not a variable of the project structures %q", name, projectConfigs))
+	}
+	return diags
+}
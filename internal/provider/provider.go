@@ -3,14 +3,18 @@ package provider
 import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
 )
 
 // Ensure ConfigMergerProvider satisfies various provider interfaces.
 var _ provider.Provider = &ConfigMergerProvider{}
+var _ provider.ProviderWithValidateConfig = &ConfigMergerProvider{}
+var _ provider.ProviderWithFunctions = &ConfigMergerProvider{}
 
 // ConfigMergerProvider defines the provider implementation.
 type ConfigMergerProvider struct {
@@ -22,8 +26,38 @@ type ConfigMergerProvider struct {
 
 // ConfigMergerProviderModel describes the provider data model.
 type ConfigMergerProviderModel struct {
-	ProjectConfig types.String   `tfsdk:"project_config"`
-	ConfigGlobs   []types.String `tfsdk:"config_globs"`
+	ProjectConfig       types.String                  `tfsdk:"project_config"`
+	ProjectConfigs      []types.String                `tfsdk:"project_configs"`
+	RootDir             types.String                  `tfsdk:"root_dir"`
+	BaseDir             types.String                  `tfsdk:"base_dir"`
+	DerivedFacts        []DerivedFactModel            `tfsdk:"derived_facts"`
+	FactsFile           types.String                  `tfsdk:"facts_file"`
+	EnvFacts            []EnvFactModel                `tfsdk:"env_facts"`
+	EnvFactsKey         types.String                  `tfsdk:"env_facts_key"`
+	AllowedValues       map[string]AllowedValuesModel `tfsdk:"allowed_values"`
+	ConfigGlobs         []types.String                `tfsdk:"config_globs"`
+	ConfigGlobsCompat   types.Bool                    `tfsdk:"config_globs_compat"`
+	ConfigGlobsPriority map[string]types.Int64        `tfsdk:"config_globs_priority"`
+	NumericPrefixOrder  types.Bool                    `tfsdk:"numeric_prefix_order"`
+	SkipEval            types.Bool                    `tfsdk:"skip_eval"`
+	Prune               []types.String                `tfsdk:"prune"`
+	CherryPick          []types.String                `tfsdk:"cherry_pick"`
+	FallbackAppend      types.Bool                    `tfsdk:"fallback_append"`
+	GoPatch             types.Bool                    `tfsdk:"go_patch"`
+	MultiDoc            types.Bool                    `tfsdk:"multi_doc"`
+	SensitivePaths      []types.String                `tfsdk:"sensitive_paths"`
+}
+
+// MergeOpts returns the merge options configured on the provider.
+func (m ConfigMergerProviderModel) MergeOpts() merger.MergeOpts {
+	return mergeOptsModel{
+		SkipEval:       m.SkipEval,
+		Prune:          m.Prune,
+		CherryPick:     m.CherryPick,
+		FallbackAppend: m.FallbackAppend,
+		GoPatch:        m.GoPatch,
+		MultiDoc:       m.MultiDoc,
+	}.apply(merger.MergeOpts{})
 }
 
 func (p *ConfigMergerProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -35,19 +69,97 @@ func (p *ConfigMergerProvider) Schema(ctx context.Context, req provider.SchemaRe
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"project_config": schema.StringAttribute{
-				MarkdownDescription: "Project Configuration",
-				Required:            true,
+				MarkdownDescription: "Project Configuration. Can be overridden on each data source, required when not set on all of them.",
+				Optional:            true,
+			},
+			"project_configs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: projectConfigsDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"root_dir": schema.StringAttribute{
+				MarkdownDescription: rootDirDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"base_dir": schema.StringAttribute{
+				MarkdownDescription: baseDirDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"derived_facts":  derivedFactsAttribute,
+			"allowed_values": allowedValuesAttribute,
+			"env_facts":      envFactsAttribute,
+			"env_facts_key": schema.StringAttribute{
+				MarkdownDescription: envFactsKeyDescription,
+				Optional:            true,
+			},
+			"facts_file": schema.StringAttribute{
+				MarkdownDescription: factsFileDescription + " Can be overridden on each data source.",
+				Optional:            true,
 			},
 			"config_globs": schema.ListAttribute{
 				ElementType:         types.StringType,
-				Required:            true,
-				Optional:            false,
-				MarkdownDescription: "List of globs to search for config files. Only last segment of each glob is considered",
+				Optional:            true,
+				MarkdownDescription: configGlobsDescription + " Can be overridden on each data source, required when not set on all of them.",
+			},
+			"config_globs_compat": schema.BoolAttribute{
+				MarkdownDescription: configGlobsCompatDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"config_globs_priority": schema.MapAttribute{
+				ElementType:         types.Int64Type,
+				MarkdownDescription: configGlobsPriorityDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"numeric_prefix_order": schema.BoolAttribute{
+				MarkdownDescription: numericPrefixOrderDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"skip_eval": schema.BoolAttribute{
+				MarkdownDescription: skipEvalDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"prune": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: pruneDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"cherry_pick": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: cherryPickDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"fallback_append": schema.BoolAttribute{
+				MarkdownDescription: fallbackAppendDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"go_patch": schema.BoolAttribute{
+				MarkdownDescription: goPatchDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"multi_doc": schema.BoolAttribute{
+				MarkdownDescription: multiDocDescription + " Can be overridden on each data source.",
+				Optional:            true,
+			},
+			"sensitive_paths": schema.ListAttribute{
+				ElementType:         types.StringType,
+				MarkdownDescription: sensitivePathsDescription + " Applies to every data source, in addition to their own `sensitive_paths`.",
+				Optional:            true,
 			},
 		},
 	}
 }
 
+func (p *ConfigMergerProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	resp.Diagnostics.Append(validateMergeOpts(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateSensitivePaths(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateProjectConfig(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateDerivedFacts(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateFactsFile(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateEnvFacts(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateAllowedValues(ctx, req.Config)...)
+	resp.Diagnostics.Append(validateConfigGlobsPriority(ctx, req.Config)...)
+}
+
 func (p *ConfigMergerProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	var data ConfigMergerProviderModel
 
@@ -73,6 +185,15 @@ func (p *ConfigMergerProvider) Resources(ctx context.Context) []func() resource.
 func (p *ConfigMergerProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewMergerDataSource,
+		NewLeavesDataSource,
+	}
+}
+
+func (p *ConfigMergerProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewMergeFunction,
+		NewDeepMergeFunction,
+		NewFactsFunction,
 	}
 }
 
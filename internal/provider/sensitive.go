@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/merger"
+)
+
+const sensitivePathsDescription = "Glob style key paths of sensitive values, for example `*.password` or `db.credentials`. " +
+	"Each dot separated segment is matched as a shell pattern and `**` matches any number of segments. " +
+	"Sensitive values, along with the values fetched by the `vault`, `awsparam` and `awssecret` operators, " +
+	"are removed from `result` and `result_object` and exposed in `sensitive_result` and `sensitive_result_object`."
+
+// validateSensitivePaths validates the patterns of the sensitive_paths attribute.
+func validateSensitivePaths(ctx context.Context, config tfsdk.Config) diag.Diagnostics {
+	return validateStringList(ctx, config, path.Root("sensitive_paths"), "Invalid Sensitive Path", func(v string) error {
+		return merger.ValidateSensitivePaths([]string{v})
+	})
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+)
+
+// validateProjectConfig checks that the project_config and project_configs attributes can be parsed, and that
+// only one of them is set.
+func validateProjectConfig(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	var projectConfig types.String
+	var projectConfigs types.List
+	diags.Append(config.GetAttribute(ctx, path.Root("project_config"), &projectConfig)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("project_configs"), &projectConfigs)...)
+	if diags.HasError() {
+		return diags
+	}
+	if !projectConfig.IsNull() && !projectConfigs.IsNull() {
+		diags.AddAttributeError(
+			path.Root("project_configs"),
+			"Conflicting Project Configuration",
+			"Only one of project_config and project_configs can be set.",
+		)
+	}
+	if !projectConfig.IsNull() && !projectConfig.IsUnknown() {
+		if _, err := envfacts.ParseProjectStructure(projectConfig.ValueString()); err != nil {
+			diags.AddAttributeError(path.Root("project_config"), "Invalid Project Configuration", err.Error())
+		}
+	}
+	diags.Append(validateStringList(ctx, config, path.Root("project_configs"), "Invalid Project Configuration", func(v string) error {
+		_, err := envfacts.ParseProjectStructure(v)
+		return err
+	})...)
+	return diags
+}
+
+// validateStringList validates every element of a list of strings attribute.
+// Unknown values are skipped as they will be validated once known.
+func validateStringList(ctx context.Context, config tfsdk.Config, p path.Path, summary string, validate func(string) error) (diags diag.Diagnostics) {
+	var list types.List
+	diags.Append(config.GetAttribute(ctx, p, &list)...)
+	if diags.HasError() || list.IsNull() || list.IsUnknown() {
+		return diags
+	}
+	for i, elem := range list.Elements() {
+		value, ok := elem.(types.String)
+		if !ok || value.IsUnknown() {
+			continue
+		}
+		if err := validate(value.ValueString()); err != nil {
+			diags.AddAttributeError(p.AtListIndex(i), summary, err.Error())
+		}
+	}
+	return diags
+}
+
+// validateFactsFile checks that the facts_file attribute is a file name, with no directory.
+func validateFactsFile(ctx context.Context, config tfsdk.Config) (diags diag.Diagnostics) {
+	var factsFile types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("facts_file"), &factsFile)...)
+	if diags.HasError() || factsFile.IsNull() || factsFile.IsUnknown() {
+		return diags
+	}
+	name := factsFile.ValueString()
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		diags.AddAttributeError(path.Root("facts_file"), "Invalid Facts File", fmt.Sprintf("facts_file needs to be a file name, got %q", name))
+	}
+	return diags
+}
@@ -0,0 +1,100 @@
+package envfacts
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AllowedValues restricts the values of a variable, either to a list of values or to the values matching a regular
+// expression.
+type AllowedValues struct {
+	Values  []string
+	Pattern string
+}
+
+// NotAllowedError is returned when the value of a variable is not one of its allowed values.
+type NotAllowedError struct {
+	Fact    string
+	Value   string
+	Dir     string
+	Allowed AllowedValues
+}
+
+func (e *NotAllowedError) Error() string {
+	if e.Allowed.Pattern != "" {
+		return fmt.Sprintf("value %q of %s in directory %q does not match the allowed pattern %q", e.Value, e.Fact, e.Dir, e.Allowed.Pattern)
+	}
+	choices := make([]string, len(e.Allowed.Values))
+	for i, v := range e.Allowed.Values {
+		choices[i] = fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprintf("value %q of %s in directory %q is not allowed, valid choices are %s", e.Value, e.Fact, e.Dir, strings.Join(choices, ", "))
+}
+
+// SetAllowedValues restricts the values of the variables of the project structure, indexed by variable name.
+// Names that are not variables of the structure are ignored.
+func (p *ProjectStructure) SetAllowedValues(allowed map[string]AllowedValues) error {
+	for i, v := range p.Vars {
+		a, ok := allowed[v.VariableName]
+		if !ok || v.IsLiteral() {
+			continue
+		}
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("allowed values of %s: %s", v.VariableName, err)
+		}
+		p.Vars[i].Allowed = &a
+		if a.Pattern != "" {
+			p.Vars[i].allowedPattern = regexp.MustCompile("^(?:" + a.Pattern + ")$")
+		}
+	}
+	return nil
+}
+
+// Validate checks that either the values or the pattern are set, and that the pattern compiles.
+func (a AllowedValues) Validate() error {
+	if (len(a.Values) == 0) == (a.Pattern == "") {
+		return fmt.Errorf("exactly one of a list of values or a pattern needs to be set")
+	}
+	if a.Pattern != "" {
+		if _, err := regexp.Compile("^(?:" + a.Pattern + ")$"); err != nil {
+			return fmt.Errorf("invalid pattern %q: %s", a.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// allows checks if the value is one of the allowed values of the variable, any value being allowed when not set.
+func (v VarMapping) allows(value string) bool {
+	if v.Allowed == nil {
+		return true
+	}
+	if v.allowedPattern != nil {
+		return v.allowedPattern.MatchString(value)
+	}
+	for _, allowed := range v.Allowed.Values {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAllowedValues checks that the mapped variables hold one of their allowed values, returning a NotAllowedError
+// for every one that does not. Missing optional levels are not checked.
+func (p *ProjectStructure) CheckAllowedValues() error {
+	errs := make([]error, 0)
+	for _, v := range p.Vars {
+		if v.IsLiteral() || v.Missing || v.allows(v.VariableValue) {
+			continue
+		}
+		errs = append(errs, &NotAllowedError{
+			Fact:    v.VariableName,
+			Value:   v.VariableValue,
+			Dir:     v.RealPath,
+			Allowed: *v.Allowed,
+		})
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package envfacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectStructure_MapPathToProjectAllowedValues(t *testing.T) {
+	allowed := map[string]AllowedValues{
+		"environment": {Values: []string{"production", "staging"}},
+		"region":      {Pattern: "[a-z]+-[a-z]+-[0-9]"},
+		"unknown":     {Values: []string{"ignored"}},
+	}
+	tests := []struct {
+		name        string
+		projectPath string
+		wantErr     string
+	}{
+		{
+			name:        "Allowed",
+			projectPath: "/base/config/production/us-west-2",
+		},
+		{
+			name:        "NotInList",
+			projectPath: "/base/config/prodution/us-west-2",
+			wantErr:     `value "prodution" of environment in directory "/base/config/prodution" is not allowed, valid choices are "production", "staging"`,
+		},
+		{
+			name:        "NotMatchingPattern",
+			projectPath: "/base/config/staging/uswest2",
+			wantErr:     `value "uswest2" of region in directory "/base/config/staging/uswest2" does not match the allowed pattern "[a-z]+-[a-z]+-[0-9]"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure("config/{{environment}}/{{region}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.SetAllowedValues(allowed); err != nil {
+				t.Fatal(err)
+			}
+			err = p.MapPathToProject(tt.projectPath, HomeDirTesting)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("MapPathToProject() error = %v", err)
+				}
+				return
+			}
+			var notAllowed *NotAllowedError
+			if !errors.As(err, &notAllowed) {
+				t.Fatalf("MapPathToProject() error = %v, want a NotAllowedError", err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("MapPathToProject() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllowedValues_Validate(t *testing.T) {
+	for name, a := range map[string]AllowedValues{
+		"None":           {},
+		"Both":           {Values: []string{"production"}, Pattern: "prod.*"},
+		"InvalidPattern": {Pattern: "prod("},
+	} {
+		if err := a.Validate(); err == nil {
+			t.Errorf("Validate() %s: expected an error", name)
+		}
+	}
+}
+
+func TestProjectStructure_FindLeavesAllowedValues(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"config/production/app", "config/prodution/app", "config/stagin/app"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := ParseProjectStructure("config/{{environment}}/{{project}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetAllowedValues(map[string]AllowedValues{"environment": {Values: []string{"production", "staging"}}}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.FindLeaves(filepath.Join(dir, "config"), HomeDirTesting)
+	if err == nil {
+		t.Fatal("FindLeaves() expected an error")
+	}
+	for _, value := range []string{`"prodution"`, `"stagin"`} {
+		if !strings.Contains(err.Error(), value) {
+			t.Errorf("FindLeaves() error = %v, want it to report %s", err, value)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package envfacts
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gookit/goutil/maputil"
+)
+
+// DerivedFact is a fact computed from the facts discovered from the path, either by looking the value of a fact up
+// in a table, or by rendering a template.
+type DerivedFact struct {
+	// Name is the dotted name of the derived fact, as in `facts.region_short`.
+	Name string
+	// Fact is the name of the fact whose value is looked up in Lookup.
+	Fact   string
+	Lookup map[string]string
+	// Default is the value of the derived fact when the value of Fact is not in Lookup, or when Fact is not set.
+	// Looking up a missing value is an error when not set.
+	Default string
+	// Template is rendered by replacing every `{{name}}` with the value of the fact, as in
+	// `{{facts.environment}}-{{facts.region_short}}`.
+	Template string
+}
+
+// errFactNotSet is returned by factValue for facts that are not set.
+var errFactNotSet = errors.New("fact is not set")
+
+// templateVar matches the facts referenced by a template.
+var templateVar = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// Validate checks that the derived fact is either a lookup or a template.
+func (d DerivedFact) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return fmt.Errorf("derived fact name can not be empty")
+	}
+	if (d.Template == "") == (d.Lookup == nil) {
+		return fmt.Errorf("derived fact %q needs either a lookup or a template", d.Name)
+	}
+	if d.Lookup != nil && d.Fact == "" {
+		return fmt.Errorf("derived fact %q needs the fact to look up", d.Name)
+	}
+	if d.Template != "" {
+		if d.Fact != "" || d.Default != "" {
+			return fmt.Errorf("derived fact %q can not have a fact or a default along with a template", d.Name)
+		}
+		for _, m := range templateVar.FindAllStringSubmatch(d.Template, -1) {
+			if m[1] == "" {
+				return fmt.Errorf("derived fact %q references an empty fact name in template %q", d.Name, d.Template)
+			}
+		}
+	}
+	return nil
+}
+
+// DeriveFacts adds the derived facts to facts, in order, so that a derived fact can use the ones before it.
+// Derived facts can not replace a fact that is already set. A derived fact using a fact that is not set, such as the
+// fact of a skipped optional level or of a project structure without it, is set to its default, or else skipped.
+func DeriveFacts(facts map[string]interface{}, derived []DerivedFact) error {
+	for _, d := range derived {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(d.Name, ".")
+		if _, ok := maputil.GetByPath(name, facts); ok {
+			return fmt.Errorf("derived fact %q is already set", d.Name)
+		}
+		value, err := d.value(facts)
+		if errors.Is(err, errFactNotSet) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := maputil.SetByPath(&facts, name, value); err != nil {
+			return fmt.Errorf("unable to add key( %s ): %q", d.Name, err)
+		}
+	}
+	return nil
+}
+
+// AddFacts adds the extra facts to facts, indexed by their dotted name, in name order. Objects are merged into the
+// objects already set under the same name, key by key. Extra facts can not replace a fact that is already set.
+func AddFacts(facts map[string]interface{}, extra map[string]interface{}) error {
+	for _, name := range sortedKeys(extra) {
+		key := strings.TrimPrefix(name, ".")
+		if key == "" {
+			return fmt.Errorf("extra fact name can not be empty")
+		}
+		if err := addFact(facts, key, extra[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFact sets the value of the fact, merging it key by key when both the value and the fact are objects.
+func addFact(facts map[string]interface{}, key string, value interface{}) error {
+	existing, ok := maputil.GetByPath(key, facts)
+	if !ok {
+		if err := maputil.SetByPath(&facts, key, value); err != nil {
+			return fmt.Errorf("unable to add key( %s ): %q", key, err)
+		}
+		return nil
+	}
+	values, isObject := objectKeys(value)
+	if _, existingIsObject := objectKeys(existing); !isObject || !existingIsObject {
+		return fmt.Errorf("extra fact %q is already set", key)
+	}
+	for _, k := range sortedKeys(values) {
+		if err := addFact(facts, key+"."+k, values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objectKeys returns the values of an object indexed by their key, and whether value is an object.
+func objectKeys(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		values := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			values[fmt.Sprint(k)] = v
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// sortedKeys returns the keys of the map, in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// value computes the value of the derived fact from the facts. The returned error wraps errFactNotSet when one of
+// the facts it uses is not set and there is no default.
+func (d DerivedFact) value(facts map[string]interface{}) (string, error) {
+	if d.Template == "" {
+		key, err := factValue(facts, d.Fact)
+		if errors.Is(err, errFactNotSet) && d.Default != "" {
+			return d.Default, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("derived fact %q: %w", d.Name, err)
+		}
+		if value, ok := d.Lookup[key]; ok {
+			return value, nil
+		}
+		if d.Default != "" {
+			return d.Default, nil
+		}
+		return "", fmt.Errorf("derived fact %q: value %q of fact %q is not in the lookup", d.Name, key, d.Fact)
+	}
+	var err error
+	value := templateVar.ReplaceAllStringFunc(d.Template, func(s string) string {
+		value, ferr := factValue(facts, templateVar.FindStringSubmatch(s)[1])
+		if ferr != nil && err == nil {
+			err = fmt.Errorf("derived fact %q: %w", d.Name, ferr)
+		}
+		return value
+	})
+	return value, err
+}
+
+// factValue returns the value of the fact, which has to be set to a single value.
+func factValue(facts map[string]interface{}, name string) (string, error) {
+	value, ok := maputil.GetByPath(strings.TrimPrefix(name, "."), facts)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", errFactNotSet, name)
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", fmt.Errorf("fact %q is not a single value", name)
+	}
+	return fmt.Sprint(value), nil
+}
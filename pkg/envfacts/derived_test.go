@@ -0,0 +1,276 @@
+package envfacts
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDeriveFacts(t *testing.T) {
+	regionShort := DerivedFact{
+		Name:   "facts.region_short",
+		Fact:   "facts.region",
+		Lookup: map[string]string{"us-west-2": "usw2", "eu-west-1": "euw1"},
+	}
+	tests := []struct {
+		name    string
+		facts   map[string]interface{}
+		derived []DerivedFact
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Lookup",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"region": "us-west-2"}},
+			derived: []DerivedFact{regionShort},
+			want:    map[string]interface{}{"facts": map[string]interface{}{"region": "us-west-2", "region_short": "usw2"}},
+		},
+		{
+			name:  "LookupDefault",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"project": "dns"}},
+			derived: []DerivedFact{{
+				Name:    "facts.cost_center",
+				Fact:    "facts.project",
+				Lookup:  map[string]string{"s3bucket": "storage"},
+				Default: "shared",
+			}},
+			want: map[string]interface{}{"facts": map[string]interface{}{"project": "dns", "cost_center": "shared"}},
+		},
+		{
+			name:    "LookupMissing",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"region": "ap-south-1"}},
+			derived: []DerivedFact{regionShort},
+			wantErr: true,
+		},
+		{
+			name:  "TemplateUsingDerivedFact",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"environment": "production", "region": "us-west-2"}},
+			derived: []DerivedFact{
+				regionShort,
+				{Name: "meta.stack", Template: "{{facts.environment}}-{{ facts.region_short }}"},
+			},
+			want: map[string]interface{}{
+				"facts": map[string]interface{}{"environment": "production", "region": "us-west-2", "region_short": "usw2"},
+				"meta":  map[string]interface{}{"stack": "production-usw2"},
+			},
+		},
+		{
+			name:  "TypedFact",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"replicas": int64(3)}},
+			derived: []DerivedFact{{
+				Name:   "facts.size",
+				Fact:   "facts.replicas",
+				Lookup: map[string]string{"3": "small"},
+			}},
+			want: map[string]interface{}{"facts": map[string]interface{}{"replicas": int64(3), "size": "small"}},
+		},
+		{
+			name:  "LookupUnsetFactDefault",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			derived: []DerivedFact{{
+				Name:    "facts.cost_center",
+				Fact:    "facts.app",
+				Lookup:  map[string]string{"api": "backend"},
+				Default: "shared",
+			}},
+			want: map[string]interface{}{"facts": map[string]interface{}{"environment": "production", "cost_center": "shared"}},
+		},
+		{
+			name:  "LookupUnsetFactSkipped",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			derived: []DerivedFact{
+				regionShort,
+				{Name: "meta.stack", Template: "{{facts.environment}}-{{facts.region_short}}"},
+			},
+			want: map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+		},
+		{
+			name:    "TemplateUnsetFactSkipped",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			derived: []DerivedFact{{Name: "facts.stack", Template: "{{facts.environment}}-{{facts.region}}"}},
+			want:    map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+		},
+		{
+			name:    "NotASingleValue",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"region": map[string]interface{}{"geo": "us"}}},
+			derived: []DerivedFact{{Name: "facts.stack", Template: "{{facts.region}}"}},
+			wantErr: true,
+		},
+		{
+			name:    "AlreadySet",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"region": "us-west-2"}},
+			derived: []DerivedFact{{Name: "facts.region", Template: "{{facts.region}}"}},
+			wantErr: true,
+		},
+		{
+			name:    "LookupAndTemplate",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"region": "us-west-2"}},
+			derived: []DerivedFact{{Name: "facts.short", Fact: "facts.region", Lookup: map[string]string{}, Template: "{{facts.region}}"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DeriveFacts(tt.facts, tt.derived)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeriveFacts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := deep.Equal(tt.facts, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("DeriveFacts() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestDeriveFactsOptionalLevel(t *testing.T) {
+	p, err := ParseProjectStructure("config/{{facts.environment}}/{{facts.region?}}/{{facts.project}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.MapPathToProject("/base/config/production/s3bucket", HomeDirTesting); err != nil {
+		t.Fatal(err)
+	}
+	facts, err := p.Facts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived := []DerivedFact{
+		{Name: "facts.region_short", Fact: "facts.region", Lookup: map[string]string{"us-west-2": "usw2"}},
+		{Name: "facts.region_group", Fact: "facts.region", Lookup: map[string]string{"us-west-2": "us"}, Default: "global"},
+		{Name: "facts.stack", Template: "{{facts.project}}-{{facts.region_short}}"},
+	}
+	if err := DeriveFacts(facts, derived); err != nil {
+		t.Fatalf("DeriveFacts() error = %v", err)
+	}
+	want := map[string]interface{}{"facts": map[string]interface{}{"environment": "production", "project": "s3bucket", "region_group": "global"}}
+	if diff := deep.Equal(facts, want); diff != nil {
+		for _, d := range diff {
+			t.Logf("DeriveFacts() differences between want and got: %v", d)
+		}
+		t.Fail()
+	}
+}
+
+func TestDeriveFactsSubFacts(t *testing.T) {
+	p, err := ParseProjectStructure("config/{{facts.environment}}/{{facts.region:(?P<geo>[a-z]+)-(?P<direction>[a-z]+)-(?P<index>[0-9]+)}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.MapPathToProject("/base/config/production/us-west-2", HomeDirTesting); err != nil {
+		t.Fatal(err)
+	}
+	facts, err := p.Facts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived := []DerivedFact{
+		{Name: "facts.region_short", Fact: "facts.region", Lookup: map[string]string{"us-west-2": "usw2"}},
+		{Name: "facts.continent", Fact: "facts.region_groups.geo", Lookup: map[string]string{"us": "america"}},
+		{Name: "facts.stack", Template: "{{facts.environment}}-{{facts.region_short}}-{{facts.region_groups.index}}"},
+	}
+	if err := DeriveFacts(facts, derived); err != nil {
+		t.Fatalf("DeriveFacts() error = %v", err)
+	}
+	want := map[string]interface{}{"facts": map[string]interface{}{
+		"environment":   "production",
+		"region":        "us-west-2",
+		"region_groups": map[string]interface{}{"geo": "us", "direction": "west", "index": "2"},
+		"region_short":  "usw2",
+		"continent":     "america",
+		"stack":         "production-usw2-2",
+	}}
+	if diff := deep.Equal(facts, want); diff != nil {
+		for _, d := range diff {
+			t.Logf("DeriveFacts() differences between want and got: %v", d)
+		}
+		t.Fail()
+	}
+}
+
+func TestAddFacts(t *testing.T) {
+	tests := []struct {
+		name    string
+		facts   map[string]interface{}
+		extra   map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "Nested",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			extra: map[string]interface{}{"facts.workspace": "blue", "meta.owner": map[interface{}]interface{}{"team": "platform"}},
+			want: map[string]interface{}{
+				"facts": map[string]interface{}{"environment": "production", "workspace": "blue"},
+				"meta":  map[string]interface{}{"owner": map[interface{}]interface{}{"team": "platform"}},
+			},
+		},
+		{
+			name:    "AlreadySet",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			extra:   map[string]interface{}{"facts.environment": "staging"},
+			wantErr: true,
+		},
+		{
+			name:  "NestedObject",
+			facts: map[string]interface{}{"facts": map[string]interface{}{"environment": "production", "region": map[string]interface{}{"geo": "us"}}},
+			extra: map[string]interface{}{
+				"facts": map[interface{}]interface{}{
+					"workspace": "blue",
+					"region":    map[interface{}]interface{}{"zone": "a"},
+				},
+				"meta": map[interface{}]interface{}{"owner": "platform"},
+			},
+			want: map[string]interface{}{
+				"facts": map[string]interface{}{
+					"environment": "production",
+					"workspace":   "blue",
+					"region":      map[string]interface{}{"geo": "us", "zone": "a"},
+				},
+				"meta": map[interface{}]interface{}{"owner": "platform"},
+			},
+		},
+		{
+			name:    "NestedObjectAlreadySet",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			extra:   map[string]interface{}{"facts": map[interface{}]interface{}{"environment": "staging"}},
+			wantErr: true,
+		},
+		{
+			name:    "ObjectReplacesFact",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			extra:   map[string]interface{}{"facts.environment": map[interface{}]interface{}{"name": "staging"}},
+			wantErr: true,
+		},
+		{
+			name:    "ReplacesNamespace",
+			facts:   map[string]interface{}{"facts": map[string]interface{}{"environment": "production"}},
+			extra:   map[string]interface{}{"facts": "flat"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AddFacts(tt.facts, tt.extra)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddFacts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := deep.Equal(tt.facts, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("AddFacts() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
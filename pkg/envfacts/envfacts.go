@@ -1,9 +1,12 @@
 package envfacts
 
 import (
+	"errors"
 	"fmt"
+	"github.com/gookit/goutil/maputil"
 	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 )
@@ -12,12 +15,51 @@ type ProjectStructure struct {
 	Root VarMapping
 	//Vars []string
 	Vars []VarMapping
+	// Wildcard is true when the structure ends with `**`, allowing any number of directories below the last level.
+	Wildcard bool
+	// Extra holds the directories matched by the wildcard, once mapped.
+	Extra []VarMapping
+	// RootDir is the directory holding the root, when set. Otherwise the root is the directory holding the
+	// RootMarker file, or the directory named after the root.
+	RootDir string
 }
 
 type VarMapping struct {
 	VariableName  string
 	VariableValue string
 	RealPath      string
+	// Optional is true when the level can be missing from the path, written `{{name?}}` or `{{name?=default}}`.
+	Optional bool
+	// Default is the value of the fact when the optional level is missing.
+	Default string
+	// Missing is true when the optional level is missing from the mapped path.
+	Missing bool
+	// Prefix and Suffix are the literals around the variable in the directory name, as in `env-{{environment}}`.
+	Prefix string
+	Suffix string
+	// Constraint is the regular expression the value has to match, as in `{{account:[0-9]{12}}}`.
+	// Its named capture groups become sub-facts under GroupsName, as in
+	// `{{region:(?P<geo>[a-z]+)-(?P<direction>[a-z]+)-(?P<index>[0-9]+)}}`.
+	Constraint string
+	// Type is the type of the value, `string`, `int` or `bool`, as in `{{replicas|int}}`. Values are strings when not set.
+	Type string
+	// Allowed restricts the values of the variable, set by SetAllowedValues. Values that are not allowed still match
+	// the level, and are reported by CheckAllowedValues.
+	Allowed *AllowedValues
+	// SubFacts holds the values of the named capture groups of the constraint, once mapped.
+	SubFacts map[string]string
+
+	constraint     *regexp.Regexp
+	allowedPattern *regexp.Regexp
+}
+
+// Level returns a description of the hierarchy level: the directory name for the root and literal levels, and
+// `name=value` for variables.
+func (v VarMapping) Level() string {
+	if v.VariableName == "" {
+		return v.VariableValue
+	}
+	return v.VariableName + "=" + v.VariableValue
 }
 
 // ExtractVar extracts the string between double brackets from the given string, trimming whitespaces.
@@ -44,19 +86,36 @@ func ParseProjectStructure(s string) (p ProjectStructure, err error) {
 		VariableValue: vars[0],
 		RealPath:      "",
 	}
-	p.Vars = make([]VarMapping, len(vars)-1)
+	p.Vars = make([]VarMapping, 0, len(vars)-1)
 	for idx, v := range vars[1:] {
-		extracted, err := ExtractVar(v)
+		if v == "**" {
+			if idx != len(vars)-2 {
+				return p, fmt.Errorf("the `**` wildcard can only be the last segment of the project structure: %q", s)
+			}
+			p.Wildcard = true
+			continue
+		}
+		mapping, err := parseSegment(v)
 		if err != nil {
 			return p, err
 		}
-		p.Vars[idx] = VarMapping{
-			VariableName: extracted,
-		}
+		p.Vars = append(p.Vars, mapping)
 	}
 	return p, nil
 }
 
+// String returns the project structure as written in the project configuration.
+func (p ProjectStructure) String() string {
+	segments := []string{p.Root.VariableValue}
+	for _, v := range p.Vars {
+		segments = append(segments, v.pattern())
+	}
+	if p.Wildcard {
+		segments = append(segments, "**")
+	}
+	return strings.Join(segments, string(filepath.Separator))
+}
+
 // GetAbsPath returns the absolute path, while also doing home directory replacement.
 func GetAbsPath(inputPath string, homeDirFunc func() (string, error)) (absPath string, err error) {
 	cleanPath := filepath.Clean(inputPath)
@@ -78,7 +137,24 @@ func GetAbsPath(inputPath string, homeDirFunc func() (string, error)) (absPath s
 	return absPath, nil
 }
 
+// ResolvePath returns the absolute path of inputPath, relative paths being resolved against baseDir when set, and
+// against the working directory otherwise. Both paths can start with `~` for the home directory.
+func ResolvePath(baseDir string, inputPath string, homeDirFunc func() (string, error)) (string, error) {
+	if baseDir == "" || inputPath == "" || filepath.IsAbs(inputPath) || strings.HasPrefix(inputPath, "~") {
+		return GetAbsPath(inputPath, homeDirFunc)
+	}
+	base, err := GetAbsPath(baseDir, homeDirFunc)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, inputPath), nil
+}
+
 // MapPathToProject maps the given path to the project structure.
+// The root is the RootDir of the structure when set, the directory holding the RootMarker file when there is one, or
+// else the directory named after the root. Several directories fully matching the structure as its root are reported
+// as an error, and so are values that are not allowed. Optional levels are mapped to a directory whenever possible, and only considered missing when the path
+// would not match the project structure otherwise.
 func (p *ProjectStructure) MapPathToProject(projectPath string, homeDirFunc func() (string, error)) (err error) {
 	absPath, err := GetAbsPath(projectPath, homeDirFunc)
 	if err != nil {
@@ -86,29 +162,171 @@ func (p *ProjectStructure) MapPathToProject(projectPath string, homeDirFunc func
 	}
 	dirs := strings.Split(absPath, string(filepath.Separator))
 	dirs[0] = string(filepath.Separator) + dirs[0]
-	rootIdx := 0
-	found := false
+	if absPath == string(filepath.Separator) {
+		dirs = dirs[:1]
+	}
+
+	candidates, err := p.rootCandidates(projectPath, dirs, homeDirFunc)
+	if err != nil {
+		return err
+	}
+	var failure matchFailure
+	roots := make([]int, 0, 1)
+	var rootAssign []int
+	for _, i := range candidates {
+		assign, ok := matchVars(p.Vars, dirs[i+1:], 0, p.Wildcard, &failure)
+		if !ok {
+			continue
+		}
+		if len(roots) == 0 {
+			rootAssign = assign
+		}
+		roots = append(roots, i)
+	}
+	switch {
+	case len(roots) > 1:
+		return fmt.Errorf("%w: projectPath %q matches project structure %q from several roots, %s: add a %q file to the root directory or set the root directory",
+			ErrAmbiguousRoot, projectPath, p.String(), joinDirs(dirs, roots), RootMarker)
+	case len(roots) == 1:
+		p.mapDirs(path.Join(dirs[:roots[0]+1]...), dirs[roots[0]+1:], rootAssign)
+		return p.CheckAllowedValues()
+	case failure.reason != "":
+		return fmt.Errorf("projectPath %q does not match project structure %q: %s", projectPath, p.String(), failure.reason)
+	}
+	return fmt.Errorf("projectPath %q does not match project structure %q", projectPath, p.String())
+}
 
-	for i := len(dirs) - 1; i >= 0; i-- {
-		if dirs[i] == p.Root.VariableValue {
-			if len(dirs[i:]) != len(p.Vars)+1 {
-				return fmt.Errorf("projectPath %q does not match project structure %q", projectPath, p)
+// MapPathToProjects maps the given path to the first of the project structures it fully matches, returning the mapped
+// structure along with its index. A structure the path matches from several roots stops the search with an
+// ErrAmbiguousRoot error, and a structure the path matches with values that are not allowed with NotAllowedError.
+func MapPathToProjects(structures []ProjectStructure, projectPath string, homeDirFunc func() (string, error)) (ProjectStructure, int, error) {
+	if len(structures) == 0 {
+		return ProjectStructure{}, -1, fmt.Errorf("no project structure to map projectPath %q to", projectPath)
+	}
+	errs := make([]error, 0, len(structures))
+	for i, structure := range structures {
+		p := structure
+		p.Vars = append([]VarMapping{}, structure.Vars...)
+		err := p.MapPathToProject(projectPath, homeDirFunc)
+		if err == nil {
+			return p, i, nil
+		}
+		var notAllowed *NotAllowedError
+		if errors.Is(err, ErrAmbiguousRoot) || errors.As(err, &notAllowed) {
+			return ProjectStructure{}, -1, err
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 1 {
+		return ProjectStructure{}, -1, errs[0]
+	}
+	reasons := make([]string, len(errs))
+	for i, err := range errs {
+		reasons[i] = err.Error()
+	}
+	return ProjectStructure{}, -1, fmt.Errorf("projectPath %q does not match any project structure:\n  - %s", projectPath, strings.Join(reasons, "\n  - "))
+}
+
+// mapDirs maps the directories below rootPath onto the project structure, as assigned by matchVars.
+// Directories following the last level are matched by the wildcard.
+func (p *ProjectStructure) mapDirs(rootPath string, dirs []string, assign []int) {
+	p.Root.RealPath = rootPath
+	last := -1
+	for i := range p.Vars {
+		if assign[i] < 0 {
+			p.Vars[i].Missing = true
+			p.Vars[i].VariableValue = p.Vars[i].Default
+			p.Vars[i].RealPath = ""
+			p.Vars[i].SubFacts = p.Vars[i].subFacts(p.Vars[i].Default)
+			continue
+		}
+		last = assign[i]
+		p.Vars[i].Missing = false
+		p.Vars[i].VariableValue, _ = p.Vars[i].match(dirs[last])
+		p.Vars[i].SubFacts = p.Vars[i].subFacts(p.Vars[i].VariableValue)
+		p.Vars[i].RealPath = path.Join(append([]string{rootPath}, dirs[:last+1]...)...)
+	}
+	p.Extra = nil
+	for i := last + 1; i < len(dirs); i++ {
+		p.Extra = append(p.Extra, VarMapping{
+			VariableValue: dirs[i],
+			RealPath:      path.Join(append([]string{rootPath}, dirs[:i+1]...)...),
+		})
+	}
+}
+
+// Levels returns the mapped levels of the hierarchy, from the root down: the root, the levels that are not missing
+// and the directories matched by the wildcard.
+func (p *ProjectStructure) Levels() []VarMapping {
+	levels := []VarMapping{p.Root}
+	for _, v := range p.Vars {
+		if !v.Missing {
+			levels = append(levels, v)
+		}
+	}
+	return append(levels, p.Extra...)
+}
+
+// IsNextLevel checks if the directory named name, inside the directory of the mapped level, can be a level of the
+// hierarchy below it: it matches the next segment of the project structure, or one of the segments after it when the
+// segments in between are optional, or the wildcard. level is the index of the level in Levels.
+func (p *ProjectStructure) IsNextLevel(level int, name string) bool {
+	next := len(p.Vars)
+	if level == 0 {
+		next = 0
+	} else {
+		seen := 0
+		for i, v := range p.Vars {
+			if v.Missing {
+				continue
+			}
+			if seen++; seen == level {
+				next = i + 1
+				break
 			}
-			found = true
-			rootIdx = i
-			break
 		}
 	}
-	if !found {
-		return fmt.Errorf("projectPath %q does not match project structure %q", projectPath, p)
+	for _, v := range p.Vars[next:] {
+		if _, err := v.match(name); err == nil {
+			return true
+		}
+		if !v.Optional {
+			return false
+		}
 	}
-	p.Root.RealPath = path.Join(dirs[:rootIdx+1]...)
-	varStartIdx := rootIdx + 1
-	for i := varStartIdx; i < len(dirs); i++ {
-		p.Vars[i-varStartIdx].RealPath = path.Join(dirs[:i+1]...)
-		p.Vars[i-varStartIdx].VariableValue = dirs[i]
+	return p.Wildcard
+}
+
+// Facts returns the facts discovered by MapPathToProject, nested according to the dotted variable names.
+// Literal levels add no facts, and missing optional levels are left unset, unless they have a default value.
+// Variables whose constraint has named capture groups also set one sub-fact per group that participated in the
+// match, under the GroupsName of the variable. Typed variables set their converted value.
+func (p *ProjectStructure) Facts() (map[string]interface{}, error) {
+	facts := make(map[string]interface{})
+	for _, v := range p.Vars {
+		if v.IsLiteral() || (v.Missing && v.Default == "") {
+			continue
+		}
+		pathKeys := strings.TrimPrefix(v.VariableName, ".")
+		value, err := v.TypedValue(v.VariableValue)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert key( %s ): %q", v.VariableName, err)
+		}
+		if err := maputil.SetByPath(&facts, pathKeys, value); err != nil {
+			return nil, fmt.Errorf("unable to add key( %s ): %q", v.VariableName, err)
+		}
+		groupsKeys := strings.TrimPrefix(v.GroupsName(), ".")
+		for _, group := range v.Groups() {
+			value, ok := v.SubFacts[group]
+			if !ok {
+				continue
+			}
+			if err := maputil.SetByPath(&facts, groupsKeys+"."+group, value); err != nil {
+				return nil, fmt.Errorf("unable to add key( %s.%s ): %q", v.GroupsName(), group, err)
+			}
+		}
 	}
-	return nil
+	return facts, nil
 }
 
 // GetFileDir returns the directory for the current source file.
@@ -45,6 +45,46 @@ func TestParseProjectStructure(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "OptionalAndWildcard",
+			args: args{s: "config/{{environment}}/{{region?}}/{{zone?=all}}/**"},
+			wantP: ProjectStructure{
+				Root: VarMapping{
+					VariableValue: "config",
+				},
+				Vars: []VarMapping{
+					{
+						VariableName: "environment",
+					},
+					{
+						VariableName: "region",
+						Optional:     true,
+					},
+					{
+						VariableName: "zone",
+						Optional:     true,
+						Default:      "all",
+					},
+				},
+				Wildcard: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "WildcardNotLast",
+			args:    args{s: "config/**/{{project}}"},
+			wantErr: true,
+		},
+		{
+			name:    "InvalidOptional",
+			args:    args{s: "config/{{region?global}}"},
+			wantErr: true,
+		},
+		{
+			name:    "EmptyName",
+			args:    args{s: "config/{{?}}"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -53,6 +93,9 @@ func TestParseProjectStructure(t *testing.T) {
 				t.Errorf("ParseProjectStructure() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			if !reflect.DeepEqual(gotP, tt.wantP) {
 				t.Errorf("ParseProjectStructure() gotP = %v, want %v", gotP, tt.wantP)
 			}
@@ -174,6 +217,57 @@ func TestGetAbsPath(t *testing.T) {
 	}
 }
 
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseDir   string
+		inputPath string
+		want      string
+	}{
+		{
+			name:      "NoBaseDir",
+			inputPath: "config/development",
+			want:      GetFileDir() + "/config/development",
+		},
+		{
+			name:      "RelativeToBaseDir",
+			baseDir:   "/srv/infra",
+			inputPath: "config/development",
+			want:      "/srv/infra/config/development",
+		},
+		{
+			name:      "BaseDirInHomeDir",
+			baseDir:   "~/infra",
+			inputPath: "./config/../config/development",
+			want:      "/home/test/infra/config/development",
+		},
+		{
+			name:      "AbsolutePath",
+			baseDir:   "/srv/infra",
+			inputPath: "/opt/config/development",
+			want:      "/opt/config/development",
+		},
+		{
+			name:      "HomeDir",
+			baseDir:   "/srv/infra",
+			inputPath: "~/config/development",
+			want:      "/home/test/config/development",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvePath(tt.baseDir, tt.inputPath, HomeDirTesting)
+			if err != nil {
+				t.Errorf("ResolvePath() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolvePath() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProjectStructure_MapPathToProject(t *testing.T) {
 	type fields struct {
 		Root VarMapping
@@ -265,3 +359,237 @@ func TestProjectStructure_MapPathToProject(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectStructure_MapPathToProjectVariableDepth(t *testing.T) {
+	tests := []struct {
+		name        string
+		structure   string
+		projectPath string
+		wantLevels  []string
+		wantFacts   map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "OptionalPresent",
+			structure:   "config/{{environment}}/{{region?}}/{{project}}",
+			projectPath: "/base/config/production/us-west-2/s3bucket",
+			wantLevels:  []string{"/base/config", "/base/config/production", "/base/config/production/us-west-2", "/base/config/production/us-west-2/s3bucket"},
+			wantFacts:   map[string]interface{}{"environment": "production", "region": "us-west-2", "project": "s3bucket"},
+		},
+		{
+			name:        "OptionalMissing",
+			structure:   "config/{{environment}}/{{region?}}/{{project}}",
+			projectPath: "/base/config/production/s3bucket",
+			wantLevels:  []string{"/base/config", "/base/config/production", "/base/config/production/s3bucket"},
+			wantFacts:   map[string]interface{}{"environment": "production", "project": "s3bucket"},
+		},
+		{
+			name:        "OptionalMissingWithDefault",
+			structure:   "config/{{environment}}/{{region?=global}}/{{project}}",
+			projectPath: "/base/config/production/s3bucket",
+			wantLevels:  []string{"/base/config", "/base/config/production", "/base/config/production/s3bucket"},
+			wantFacts:   map[string]interface{}{"environment": "production", "region": "global", "project": "s3bucket"},
+		},
+		{
+			name:        "LaterOptionalSkippedFirst",
+			structure:   "config/{{environment}}/{{region?}}/{{zone?}}",
+			projectPath: "/base/config/production/us-west-2",
+			wantLevels:  []string{"/base/config", "/base/config/production", "/base/config/production/us-west-2"},
+			wantFacts:   map[string]interface{}{"environment": "production", "region": "us-west-2"},
+		},
+		{
+			name:        "Wildcard",
+			structure:   "config/{{environment}}/**",
+			projectPath: "/base/config/production/app/nested",
+			wantLevels:  []string{"/base/config", "/base/config/production", "/base/config/production/app", "/base/config/production/app/nested"},
+			wantFacts:   map[string]interface{}{"environment": "production"},
+		},
+		{
+			name:        "WildcardEmpty",
+			structure:   "config/{{environment}}/**",
+			projectPath: "/base/config/production",
+			wantLevels:  []string{"/base/config", "/base/config/production"},
+			wantFacts:   map[string]interface{}{"environment": "production"},
+		},
+		{
+			name:        "SubFacts",
+			structure:   "config/{{environment}}/{{region:(?P<geo>[a-z]+)-(?P<direction>[a-z]+)-(?P<index>[0-9]+)}}",
+			projectPath: "/base/config/production/us-west-2",
+			wantLevels:  []string{"/base/config", "/base/config/production", "/base/config/production/us-west-2"},
+			wantFacts: map[string]interface{}{
+				"environment":   "production",
+				"region":        "us-west-2",
+				"region_groups": map[string]interface{}{"geo": "us", "direction": "west", "index": "2"},
+			},
+		},
+		{
+			name:        "LiteralLevels",
+			structure:   "config/{{environment}}/regions/{{region}}/apps/{{app}}",
+			projectPath: "/base/config/production/regions/us-west-2/apps/web",
+			wantLevels: []string{
+				"/base/config", "/base/config/production", "/base/config/production/regions",
+				"/base/config/production/regions/us-west-2", "/base/config/production/regions/us-west-2/apps",
+				"/base/config/production/regions/us-west-2/apps/web",
+			},
+			wantFacts: map[string]interface{}{"environment": "production", "region": "us-west-2", "app": "web"},
+		},
+		{
+			name:        "LiteralMismatch",
+			structure:   "config/{{environment}}/regions/{{region}}",
+			projectPath: "/base/config/production/zones/us-west-2",
+			wantErr:     true,
+		},
+		{
+			name:        "TooDeep",
+			structure:   "config/{{environment}}/{{region?}}",
+			projectPath: "/base/config/production/us-west-2/s3bucket",
+			wantErr:     true,
+		},
+		{
+			name:        "TooShallow",
+			structure:   "config/{{environment}}/{{project}}",
+			projectPath: "/base/config/production",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure(tt.structure)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.MapPathToProject(tt.projectPath, HomeDirTesting)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapPathToProject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			gotLevels := make([]string, 0)
+			for _, level := range p.Levels() {
+				gotLevels = append(gotLevels, level.RealPath)
+			}
+			gotFacts, err := p.Facts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, diff := range [][]string{deep.Equal(gotLevels, tt.wantLevels), deep.Equal(gotFacts, tt.wantFacts)} {
+				for _, d := range diff {
+					t.Logf("MapPathToProject() differences between want and got: %v", d)
+				}
+				if diff != nil {
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestMapPathToProjects(t *testing.T) {
+	structures := []string{
+		"config/{{environment}}/{{region}}/{{project}}",
+		"config/{{environment}}/{{project}}",
+	}
+	tests := []struct {
+		name        string
+		projectPath string
+		wantIndex   int
+		wantFacts   map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "FirstStructure",
+			projectPath: "/base/config/production/us-west-2/s3bucket",
+			wantIndex:   0,
+			wantFacts:   map[string]interface{}{"environment": "production", "region": "us-west-2", "project": "s3bucket"},
+		},
+		{
+			name:        "SecondStructure",
+			projectPath: "/base/config/production/s3bucket",
+			wantIndex:   1,
+			wantFacts:   map[string]interface{}{"environment": "production", "project": "s3bucket"},
+		},
+		{
+			name:        "NoStructure",
+			projectPath: "/base/config/production",
+			wantIndex:   -1,
+			wantErr:     true,
+		},
+	}
+	parsed := make([]ProjectStructure, 0, len(structures))
+	for _, s := range structures {
+		p, err := ParseProjectStructure(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, p)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, index, err := MapPathToProjects(parsed, tt.projectPath, HomeDirTesting)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapPathToProjects() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if index != tt.wantIndex {
+				t.Errorf("MapPathToProjects() index = %v, want %v", index, tt.wantIndex)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotFacts, err := p.Facts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(gotFacts, tt.wantFacts); diff != nil {
+				for _, d := range diff {
+					t.Logf("MapPathToProjects() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+	// the structures themselves are left untouched
+	for i, p := range parsed {
+		if p.String() != structures[i] {
+			t.Errorf("MapPathToProjects() modified structure %d: %q", i, p.String())
+		}
+		for _, v := range p.Vars {
+			if v.RealPath != "" {
+				t.Errorf("MapPathToProjects() modified structure %d: level %q mapped to %q", i, v.VariableName, v.RealPath)
+			}
+		}
+	}
+}
+
+func TestProjectStructure_IsNextLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		structure   string
+		projectPath string
+		level       int
+		dir         string
+		want        bool
+	}{
+		{name: "NextSegment", structure: "config/{{env:prod|staging}}/{{app}}", projectPath: "/srv/config/prod/app", level: 0, dir: "staging", want: true},
+		{name: "NotNextSegment", structure: "config/{{env:prod|staging}}/{{app}}", projectPath: "/srv/config/prod/app", level: 0, dir: "conf.d", want: false},
+		{name: "LastLevel", structure: "config/{{env}}/{{app}}", projectPath: "/srv/config/prod/app", level: 2, dir: "conf.d", want: false},
+		{name: "AfterOptional", structure: "config/{{region?:us-.*}}/{{app:[a-z]+}}", projectPath: "/srv/config/app", level: 0, dir: "web", want: true},
+		{name: "Wildcard", structure: "config/{{env}}/**", projectPath: "/srv/config/prod/a", level: 2, dir: "b", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure(tt.structure)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.MapPathToProject(tt.projectPath, HomeDirTesting); err != nil {
+				t.Fatal(err)
+			}
+			if got := p.IsNextLevel(tt.level, tt.dir); got != tt.want {
+				t.Errorf("IsNextLevel(%d, %q) = %v, want %v", tt.level, tt.dir, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package envfacts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindLeaves walks rootPath, the directory holding the root of the project structure, and returns the project
+// structure mapped to every directory that fully matches it, in directory order. When optional levels allow a
+// directory and one of its descendants to both match, only the most specific one is returned. Directories below the
+// last level are not walked, even when the structure ends with a wildcard. Hidden directories are skipped.
+// Leaves holding values that are not allowed are all reported as NotAllowedError, joined.
+func (p *ProjectStructure) FindLeaves(rootPath string, homeDirFunc func() (string, error)) ([]ProjectStructure, error) {
+	absRoot, err := GetAbsPath(rootPath, homeDirFunc)
+	if err != nil {
+		return nil, err
+	}
+	isRoot, err := p.IsRoot(absRoot, homeDirFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !isRoot {
+		return nil, fmt.Errorf("root path %q does not match the root %q of the project structure", rootPath, p.Root.VariableValue)
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("root path %q is not a directory", rootPath)
+	}
+
+	type match struct {
+		dirs   []string
+		assign []int
+	}
+	matches := make([]match, 0)
+	// ancestors holds the directories that have a matching descendant
+	ancestors := make(map[string]bool)
+	var walk func(dir string, dirs []string) error
+	walk = func(dir string, dirs []string) error {
+		if assign, ok := matchVars(p.Vars, dirs, 0, false, nil); ok {
+			matches = append(matches, match{dirs: dirs, assign: assign})
+			for i := range dirs {
+				ancestors[strings.Join(dirs[:i], "/")] = true
+			}
+		}
+		if len(dirs) == len(p.Vars) {
+			return nil
+		}
+		// entries are sorted by name, so leaves are found in directory order
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			full := filepath.Join(dir, entry.Name())
+			if !isDir(entry, full) {
+				continue
+			}
+			if err := walk(full, append(dirs[:len(dirs):len(dirs)], entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(absRoot, nil); err != nil {
+		return nil, err
+	}
+
+	leaves := make([]ProjectStructure, 0, len(matches))
+	errs := make([]error, 0)
+	for _, m := range matches {
+		if ancestors[strings.Join(m.dirs, "/")] {
+			continue
+		}
+		leaf := ProjectStructure{
+			Root:    p.Root,
+			Vars:    append([]VarMapping{}, p.Vars...),
+			RootDir: p.RootDir,
+		}
+		leaf.mapDirs(absRoot, m.dirs, m.assign)
+		if err := leaf.CheckAllowedValues(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		leaves = append(leaves, leaf)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return leaves, nil
+}
+
+// isDir checks if the directory entry is a directory, following symbolic links.
+func isDir(entry os.DirEntry, full string) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(full)
+	return err == nil && info.IsDir()
+}
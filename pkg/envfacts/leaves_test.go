@@ -0,0 +1,125 @@
+package envfacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestProjectStructure_FindLeaves(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{
+		"config/production/us-west-2/s3bucket",
+		"config/production/us-west-2/.hidden",
+		"config/production/eu-west-1",
+		"config/development/us-east-2/s3bucket",
+		"config/development/us-east-2/ec2",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config/production/us-west-2/config.yaml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ParseProjectStructure("config/{{environment}}/{{region}}/{{project}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		rootPath string
+		want     [][]string
+		wantErr  bool
+	}{
+		{
+			name:     "Leaves",
+			rootPath: filepath.Join(dir, "config"),
+			want: [][]string{
+				{"development", "us-east-2", "ec2"},
+				{"development", "us-east-2", "s3bucket"},
+				{"production", "us-west-2", "s3bucket"},
+			},
+		},
+		{
+			name:     "RootMismatch",
+			rootPath: dir,
+			wantErr:  true,
+		},
+		{
+			name:     "MissingRoot",
+			rootPath: filepath.Join(dir, "missing", "config"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaves, err := p.FindLeaves(tt.rootPath, HomeDirTesting)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindLeaves() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got := make([][]string, len(leaves))
+			for i, leaf := range leaves {
+				got[i] = make([]string, len(leaf.Vars))
+				for j, v := range leaf.Vars {
+					got[i][j] = v.VariableValue
+				}
+				last := leaf.Vars[len(leaf.Vars)-1].RealPath
+				if want := filepath.Join(append([]string{tt.rootPath}, got[i]...)...); last != want {
+					t.Errorf("FindLeaves() leaf path = %q, want %q", last, want)
+				}
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("FindLeaves() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestProjectStructure_FindLeavesOptional(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{
+		"config/production/us-west-2/s3bucket",
+		"config/production/ec2",
+		"config/development",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := ParseProjectStructure("config/{{environment}}/{{region?}}/{{project}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves, err := p.FindLeaves(filepath.Join(dir, "config"), HomeDirTesting)
+	if err != nil {
+		t.Fatalf("FindLeaves() error = %v", err)
+	}
+	got := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		levels := leaf.Levels()
+		got[i] = levels[len(levels)-1].RealPath
+	}
+	want := []string{
+		filepath.Join(dir, "config/production/ec2"),
+		filepath.Join(dir, "config/production/us-west-2/s3bucket"),
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		for _, d := range diff {
+			t.Logf("FindLeaves() differences between want and got: %v", d)
+		}
+		t.Fail()
+	}
+}
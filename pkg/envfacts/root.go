@@ -0,0 +1,88 @@
+package envfacts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RootMarker is the name of the file marking the root directory of the hierarchy. The name of a marked directory does
+// not have to match the root of the project structure.
+const RootMarker = ".config-merger-root"
+
+// ErrAmbiguousRoot is returned when several directories can hold the root of the project structure.
+var ErrAmbiguousRoot = errors.New("ambiguous project root")
+
+// hasRootMarker checks if the directory holds the root marker file.
+func hasRootMarker(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, RootMarker))
+	return err == nil && !info.IsDir()
+}
+
+// rootCandidates returns the indexes of the directories that can hold the root of the project structure, from the
+// deepest one up. The root directory of the structure wins over marker files, which win over the directory name.
+func (p *ProjectStructure) rootCandidates(projectPath string, dirs []string, homeDirFunc func() (string, error)) ([]int, error) {
+	if p.RootDir != "" {
+		absRoot, err := GetAbsPath(p.RootDir, homeDirFunc)
+		if err != nil {
+			return nil, err
+		}
+		rootDirs := strings.Split(absRoot, string(filepath.Separator))
+		rootDirs[0] = string(filepath.Separator) + rootDirs[0]
+		if absRoot == string(filepath.Separator) {
+			rootDirs = rootDirs[:1]
+		}
+		if len(rootDirs) > len(dirs) || path.Join(dirs[:len(rootDirs)]...) != path.Join(rootDirs...) {
+			return nil, fmt.Errorf("projectPath %q is not below the root directory %q", projectPath, p.RootDir)
+		}
+		return []int{len(rootDirs) - 1}, nil
+	}
+
+	markers := make([]int, 0)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if hasRootMarker(path.Join(dirs[:i+1]...)) {
+			markers = append(markers, i)
+		}
+	}
+	switch len(markers) {
+	case 0:
+	case 1:
+		return markers, nil
+	default:
+		return nil, fmt.Errorf("%w: projectPath %q has several root markers %q, in %s: keep a single one or set the root directory",
+			ErrAmbiguousRoot, projectPath, RootMarker, joinDirs(dirs, markers))
+	}
+
+	candidates := make([]int, 0)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i] == p.Root.VariableValue {
+			candidates = append(candidates, i)
+		}
+	}
+	return candidates, nil
+}
+
+// IsRoot checks if dir, an absolute path, can hold the root of the project structure: it is the root directory of
+// the structure when set, or it holds the root marker file, or its name matches the root of the structure.
+func (p *ProjectStructure) IsRoot(dir string, homeDirFunc func() (string, error)) (bool, error) {
+	if p.RootDir != "" {
+		absRoot, err := GetAbsPath(p.RootDir, homeDirFunc)
+		if err != nil {
+			return false, err
+		}
+		return absRoot == dir, nil
+	}
+	return hasRootMarker(dir) || filepath.Base(dir) == p.Root.VariableValue, nil
+}
+
+// joinDirs lists the paths of the directories at the given indexes, quoted.
+func joinDirs(dirs []string, indexes []int) string {
+	paths := make([]string, len(indexes))
+	for i, idx := range indexes {
+		paths[i] = fmt.Sprintf("%q", path.Join(dirs[:idx+1]...))
+	}
+	return strings.Join(paths, ", ")
+}
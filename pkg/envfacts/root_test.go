@@ -0,0 +1,169 @@
+package envfacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestProjectStructure_MapPathToProjectRoot(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{
+		"config/checkout/hierarchy/production/s3bucket",
+		"nested/hierarchy/production/inner/production/s3bucket",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, marker := range []string{
+		"config/checkout/hierarchy",
+		"nested/hierarchy",
+		"nested/hierarchy/production/inner",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, marker, RootMarker), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		structure   string
+		rootDir     string
+		projectPath string
+		wantRoot    string
+		wantFacts   map[string]interface{}
+		wantErr     bool
+		// wantAmbiguous is true when the error is expected to be ErrAmbiguousRoot
+		wantAmbiguous bool
+	}{
+		{
+			name:        "FactNamedLikeRoot",
+			structure:   "config/{{environment}}/{{project}}",
+			projectPath: "/base/config/production/config",
+			wantRoot:    "/base/config",
+			wantFacts:   map[string]interface{}{"environment": "production", "project": "config"},
+		},
+		{
+			name:          "AmbiguousName",
+			structure:     "config/{{environment}}/**",
+			projectPath:   "/base/config/production/config/s3bucket",
+			wantErr:       true,
+			wantAmbiguous: true,
+		},
+		{
+			name:        "RootDir",
+			structure:   "config/{{environment}}/**",
+			rootDir:     "/base/config",
+			projectPath: "/base/config/production/config/s3bucket",
+			wantRoot:    "/base/config",
+			wantFacts:   map[string]interface{}{"environment": "production"},
+		},
+		{
+			name:        "RootDirWithOtherName",
+			structure:   "config/{{environment}}/{{project}}",
+			rootDir:     "/base/hierarchy",
+			projectPath: "/base/hierarchy/production/s3bucket",
+			wantRoot:    "/base/hierarchy",
+			wantFacts:   map[string]interface{}{"environment": "production", "project": "s3bucket"},
+		},
+		{
+			name:        "OutsideRootDir",
+			structure:   "config/{{environment}}/{{project}}",
+			rootDir:     "/base/config",
+			projectPath: "/base/configuration/production/s3bucket",
+			wantErr:     true,
+		},
+		{
+			name:        "Marker",
+			structure:   "config/{{environment}}/{{project}}",
+			projectPath: filepath.Join(dir, "config/checkout/hierarchy/production/s3bucket"),
+			wantRoot:    filepath.Join(dir, "config/checkout/hierarchy"),
+			wantFacts:   map[string]interface{}{"environment": "production", "project": "s3bucket"},
+		},
+		{
+			name:          "SeveralMarkers",
+			structure:     "config/{{environment}}/**",
+			projectPath:   filepath.Join(dir, "nested/hierarchy/production/inner/production/s3bucket"),
+			wantErr:       true,
+			wantAmbiguous: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure(tt.structure)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.RootDir = tt.rootDir
+			err = p.MapPathToProject(tt.projectPath, HomeDirTesting)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapPathToProject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(err, ErrAmbiguousRoot) != tt.wantAmbiguous {
+				t.Errorf("MapPathToProject() error = %v, wantAmbiguous %v", err, tt.wantAmbiguous)
+			}
+			if tt.wantErr {
+				return
+			}
+			if p.Root.RealPath != tt.wantRoot {
+				t.Errorf("MapPathToProject() root = %v, want %v", p.Root.RealPath, tt.wantRoot)
+			}
+			gotFacts, err := p.Facts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(gotFacts, tt.wantFacts); diff != nil {
+				for _, d := range diff {
+					t.Logf("MapPathToProject() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestProjectStructure_IsRoot(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"config", "hierarchy", "other"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "hierarchy", RootMarker), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		rootDir string
+		dir     string
+		want    bool
+	}{
+		{name: "Name", dir: filepath.Join(dir, "config"), want: true},
+		{name: "Marker", dir: filepath.Join(dir, "hierarchy"), want: true},
+		{name: "Other", dir: filepath.Join(dir, "other"), want: false},
+		{name: "RootDir", rootDir: filepath.Join(dir, "other"), dir: filepath.Join(dir, "other"), want: true},
+		{name: "NotRootDir", rootDir: filepath.Join(dir, "other"), dir: filepath.Join(dir, "config"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure("config/{{environment}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.RootDir = tt.rootDir
+			got, err := p.IsRoot(tt.dir, HomeDirTesting)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("IsRoot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
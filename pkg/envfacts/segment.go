@@ -0,0 +1,259 @@
+package envfacts
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseSegment parses a segment of the project structure: a literal directory name, or a variable optionally
+// surrounded by literals: `{{name}}`, `env-{{name}}`, `{{name:regex}}` to constrain the value, and `{{name?}}` or
+// `{{name?=default}}` for optional levels, and `{{name|int}}` or `{{name|bool}}` to type the value. The type follows the
+// name and the constraint comes last, as in `{{name|int?=default:regex}}`.
+// Literal segments are returned with an empty VariableName and the directory name as VariableValue, like the root.
+func parseSegment(s string) (v VarMapping, err error) {
+	if s == "" {
+		return v, fmt.Errorf("segment can not be empty")
+	}
+	start := strings.Index(s, "{{")
+	if start < 0 {
+		if strings.Contains(s, "}}") {
+			return v, fmt.Errorf("unexpected closing brackets in literal segment: %q", s)
+		}
+		v.VariableValue = s
+		return v, nil
+	}
+	end := closingBrackets(s, start+2)
+	if end < 0 {
+		return v, fmt.Errorf("variable is not closed by double brackets: %q", s)
+	}
+	v.Prefix = s[:start]
+	v.Suffix = s[end+2:]
+	if strings.Contains(v.Prefix, "}}") {
+		return v, fmt.Errorf("unexpected closing brackets before the variable: %q", s)
+	}
+	if strings.Contains(v.Suffix, "{{") || strings.Contains(v.Suffix, "}}") {
+		return v, fmt.Errorf("segment can only hold one variable: %q", s)
+	}
+
+	name := s[start+2 : end]
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		v.Constraint = name[idx+1:]
+		name = name[:idx]
+		if v.Constraint == "" {
+			return v, fmt.Errorf("constraint can not be empty: %q", s)
+		}
+		v.constraint, err = regexp.Compile("^(?:" + v.Constraint + ")$")
+		if err != nil {
+			return v, fmt.Errorf("invalid constraint %q in %q: %s", v.Constraint, s, err)
+		}
+		seen := make(map[string]bool)
+		for _, group := range v.Groups() {
+			if seen[group] {
+				return v, fmt.Errorf("capture group %q is defined more than once in %q", group, s)
+			}
+			seen[group] = true
+		}
+	}
+	if idx := strings.Index(name, "?"); idx >= 0 {
+		rest := name[idx+1:]
+		name = name[:idx]
+		v.Optional = true
+		if rest != "" {
+			if !strings.HasPrefix(rest, "=") {
+				return v, fmt.Errorf("optional variable needs to be written as `{{name?}}` or `{{name?=default}}`: %q", s)
+			}
+			v.Default = strings.TrimSpace(rest[1:])
+		}
+	}
+	if idx := strings.Index(name, "|"); idx >= 0 {
+		v.Type = strings.TrimSpace(name[idx+1:])
+		name = name[:idx]
+		if !validTypes[v.Type] {
+			return v, fmt.Errorf("type %q needs to be one of string, int or bool: %q", v.Type, s)
+		}
+		if v.Type != "string" && len(v.Groups()) > 0 {
+			return v, fmt.Errorf("type %q can not be used along with named capture groups: %q", v.Type, s)
+		}
+	}
+	v.VariableName = strings.TrimSpace(name)
+	if v.VariableName == "" {
+		return v, fmt.Errorf("variable name can not be empty: %q", s)
+	}
+	if v.Default != "" && v.constraint != nil && !v.constraint.MatchString(v.Default) {
+		return v, fmt.Errorf("default %q does not match the constraint %q: %q", v.Default, v.Constraint, s)
+	}
+	if v.Default != "" {
+		if _, err := v.TypedValue(v.Default); err != nil {
+			return v, fmt.Errorf("default %q is not a valid %s: %q", v.Default, v.Type, s)
+		}
+	}
+	return v, nil
+}
+
+// validTypes are the types a variable can be declared with.
+var validTypes = map[string]bool{"string": true, "int": true, "bool": true}
+
+// TypedValue converts the value to the type of the variable: an int64 for `int`, a bool for `bool` and the value
+// itself otherwise. Booleans are written `true` or `false`, in any case.
+func (v VarMapping) TypedValue(value string) (interface{}, error) {
+	switch v.Type {
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "bool":
+		switch strings.ToLower(value) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", value)
+	}
+	return value, nil
+}
+
+// closingBrackets returns the index of the double brackets closing the variable opened before from, skipping the
+// braces of the constraint, as in `{{account:[0-9]{12}}}`. It returns -1 when the variable is not closed.
+func closingBrackets(s string, from int) int {
+	depth := 0
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '}' {
+				return i
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// IsLiteral checks if the level is a literal directory name, as the root is.
+func (v VarMapping) IsLiteral() bool {
+	return v.VariableName == ""
+}
+
+// pattern returns the segment as written in the project configuration.
+func (v VarMapping) pattern() string {
+	if v.IsLiteral() {
+		return v.VariableValue
+	}
+	name := v.VariableName
+	if v.Type != "" {
+		name += "|" + v.Type
+	}
+	if v.Optional {
+		name += "?"
+		if v.Default != "" {
+			name += "=" + v.Default
+		}
+	}
+	if v.Constraint != "" {
+		name += ":" + v.Constraint
+	}
+	return v.Prefix + "{{" + name + "}}" + v.Suffix
+}
+
+// match extracts the value of the variable from the directory name, checking the literals and the constraint.
+// Literal segments have to match the directory name exactly.
+func (v VarMapping) match(dir string) (string, error) {
+	if v.IsLiteral() {
+		if dir != v.VariableValue {
+			return "", fmt.Errorf("directory %q does not match %q", dir, v.VariableValue)
+		}
+		return dir, nil
+	}
+	if len(dir) <= len(v.Prefix)+len(v.Suffix) || !strings.HasPrefix(dir, v.Prefix) || !strings.HasSuffix(dir, v.Suffix) {
+		return "", fmt.Errorf("directory %q does not match %q", dir, v.pattern())
+	}
+	value := dir[len(v.Prefix) : len(dir)-len(v.Suffix)]
+	if v.constraint != nil && !v.constraint.MatchString(value) {
+		return "", fmt.Errorf("value %q of %s in directory %q does not match the constraint %q", value, v.VariableName, dir, v.Constraint)
+	}
+	if _, err := v.TypedValue(value); err != nil {
+		return "", fmt.Errorf("value %q of %s in directory %q is not a valid %s", value, v.VariableName, dir, v.Type)
+	}
+	return value, nil
+}
+
+// Groups returns the names of the named capture groups of the constraint, in order.
+func (v VarMapping) Groups() []string {
+	if v.constraint == nil {
+		return nil
+	}
+	groups := make([]string, 0)
+	for _, name := range v.constraint.SubexpNames() {
+		if name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups
+}
+
+// GroupsName returns the name of the fact holding the named capture groups of the constraint, the variable name
+// followed by `_groups`, as in `facts.region_groups` for `facts.region`.
+func (v VarMapping) GroupsName() string {
+	return v.VariableName + "_groups"
+}
+
+// subFacts returns the values of the named capture groups of the constraint that participated in the match of
+// value, nil when the constraint has no named group.
+func (v VarMapping) subFacts(value string) map[string]string {
+	if len(v.Groups()) == 0 || value == "" {
+		return nil
+	}
+	indexes := v.constraint.FindStringSubmatchIndex(value)
+	if indexes == nil {
+		return nil
+	}
+	facts := make(map[string]string)
+	for i, name := range v.constraint.SubexpNames() {
+		if name == "" || indexes[2*i] < 0 {
+			continue
+		}
+		facts[name] = value[indexes[2*i]:indexes[2*i+1]]
+	}
+	return facts
+}
+
+// matchFailure records why the deepest directory that was tried could not be mapped, to explain mapping errors.
+type matchFailure struct {
+	depth  int
+	reason string
+}
+
+func (f *matchFailure) record(depth int, err error) {
+	if f != nil && depth >= f.depth {
+		f.depth = depth
+		f.reason = err.Error()
+	}
+}
+
+// matchVars maps the directories, starting from next, onto the variables. It returns the index of the directory of
+// every variable, -1 for the missing optional levels, and false when the directories do not match. Optional levels are
+// mapped to a directory whenever possible.
+func matchVars(vars []VarMapping, dirs []string, next int, wildcard bool, failure *matchFailure) ([]int, bool) {
+	if len(vars) == 0 {
+		return []int{}, next == len(dirs) || wildcard
+	}
+	if next < len(dirs) {
+		if _, err := vars[0].match(dirs[next]); err != nil {
+			failure.record(next, err)
+		} else if rest, ok := matchVars(vars[1:], dirs, next+1, wildcard, failure); ok {
+			return append([]int{next}, rest...), true
+		}
+	}
+	if vars[0].Optional {
+		if rest, ok := matchVars(vars[1:], dirs, next, wildcard, failure); ok {
+			return append([]int{-1}, rest...), true
+		}
+	}
+	return nil, false
+}
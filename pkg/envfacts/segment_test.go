@@ -0,0 +1,268 @@
+package envfacts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestParseSegment(t *testing.T) {
+	tests := []struct {
+		name        string
+		segment     string
+		wantName    string
+		wantPrefix  string
+		wantSuffix  string
+		wantPattern string
+		wantErr     bool
+	}{
+		{
+			name:        "Variable",
+			segment:     "{{ environment }}",
+			wantName:    "environment",
+			wantPattern: "{{environment}}",
+		},
+		{
+			name:        "PrefixAndSuffix",
+			segment:     "env-{{facts.environment}}-eu",
+			wantName:    "facts.environment",
+			wantPrefix:  "env-",
+			wantSuffix:  "-eu",
+			wantPattern: "env-{{facts.environment}}-eu",
+		},
+		{
+			name:        "Constraint",
+			segment:     "acct-{{facts.account:[0-9]{12}}}",
+			wantName:    "facts.account",
+			wantPrefix:  "acct-",
+			wantPattern: "acct-{{facts.account:[0-9]{12}}}",
+		},
+		{
+			name:        "OptionalWithDefaultAndConstraint",
+			segment:     "{{region?=global:[a-z]+(-[a-z]+-[0-9])?}}",
+			wantName:    "region",
+			wantPattern: "{{region?=global:[a-z]+(-[a-z]+-[0-9])?}}",
+		},
+		{
+			name:        "TypedOptional",
+			segment:     "replicas-{{ replicas | int ?=1:[0-9]+}}",
+			wantName:    "replicas",
+			wantPrefix:  "replicas-",
+			wantPattern: "replicas-{{replicas|int?=1:[0-9]+}}",
+		},
+		{
+			name:        "Literal",
+			segment:     "regions",
+			wantPattern: "regions",
+		},
+		{
+			name:    "Empty",
+			segment: "",
+			wantErr: true,
+		},
+		{
+			name:    "LiteralWithClosingBrackets",
+			segment: "regions}}",
+			wantErr: true,
+		},
+		{
+			name:    "NotClosed",
+			segment: "{{account:[0-9]{12}",
+			wantErr: true,
+		},
+		{
+			name:    "TwoVariables",
+			segment: "{{environment}}-{{region}}",
+			wantErr: true,
+		},
+		{
+			name:    "InvalidConstraint",
+			segment: "{{account:[0-9}}",
+			wantErr: true,
+		},
+		{
+			name:    "DuplicateCaptureGroup",
+			segment: "{{region:(?P<geo>[a-z]+)-(?P<geo>[a-z]+)}}",
+			wantErr: true,
+		},
+		{
+			name:    "UnknownType",
+			segment: "{{replicas|float}}",
+			wantErr: true,
+		},
+		{
+			name:    "DefaultOfWrongType",
+			segment: "{{enabled|bool?=yes}}",
+			wantErr: true,
+		},
+		{
+			name:    "TypeWithCaptureGroups",
+			segment: "{{region|int:(?P<index>[0-9]+)}}",
+			wantErr: true,
+		},
+		{
+			name:    "DefaultDoesNotMatchConstraint",
+			segment: "{{region?=global:[0-9]+}}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSegment(tt.segment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSegment() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.VariableName != tt.wantName || got.Prefix != tt.wantPrefix || got.Suffix != tt.wantSuffix {
+				t.Errorf("parseSegment() got name %q, prefix %q, suffix %q, want %q, %q, %q",
+					got.VariableName, got.Prefix, got.Suffix, tt.wantName, tt.wantPrefix, tt.wantSuffix)
+			}
+			if got.pattern() != tt.wantPattern {
+				t.Errorf("pattern() got = %q, want %q", got.pattern(), tt.wantPattern)
+			}
+		})
+	}
+}
+
+func TestProjectStructure_MapPathToProjectTemplates(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectPath string
+		wantValues  []string
+		wantErr     string
+	}{
+		{
+			name:        "Match",
+			projectPath: "/base/config/env-production/acct-123456789012",
+			wantValues:  []string{"production", "123456789012"},
+		},
+		{
+			name:        "PrefixMismatch",
+			projectPath: "/base/config/production/acct-123456789012",
+			wantErr:     `directory "production" does not match "env-{{facts.environment}}"`,
+		},
+		{
+			name:        "ConstraintMismatch",
+			projectPath: "/base/config/env-production/acct-1234",
+			wantErr:     `value "1234" of facts.account in directory "acct-1234" does not match the constraint "[0-9]{12}"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure("config/env-{{facts.environment}}/acct-{{facts.account:[0-9]{12}}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.MapPathToProject(tt.projectPath, HomeDirTesting)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("MapPathToProject() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MapPathToProject() error = %v", err)
+			}
+			for i, v := range p.Vars {
+				if v.VariableValue != tt.wantValues[i] {
+					t.Errorf("MapPathToProject() %s = %q, want %q", v.VariableName, v.VariableValue, tt.wantValues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVarMapping_SubFacts(t *testing.T) {
+	v, err := parseSegment("{{region:(?P<geo>[a-z]+)-(?P<direction>[a-z]+)(-(?P<index>[0-9]+))?}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(v.Groups(), []string{"geo", "direction", "index"}); diff != nil {
+		t.Errorf("Groups() differences between want and got: %v", diff)
+	}
+	tests := []struct {
+		value string
+		want  map[string]string
+	}{
+		{
+			value: "us-west-2",
+			want:  map[string]string{"geo": "us", "direction": "west", "index": "2"},
+		},
+		{
+			value: "eu-central",
+			want:  map[string]string{"geo": "eu", "direction": "central"},
+		},
+	}
+	for _, tt := range tests {
+		if diff := deep.Equal(v.subFacts(tt.value), tt.want); diff != nil {
+			for _, d := range diff {
+				t.Logf("subFacts(%q) differences between want and got: %v", tt.value, d)
+			}
+			t.Fail()
+		}
+	}
+}
+
+func TestProjectStructure_MapPathToProjectTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectPath string
+		wantFacts   map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "Typed",
+			projectPath: "/base/config/3/enabled-TRUE/2024",
+			wantFacts: map[string]interface{}{
+				"facts": map[string]interface{}{"replicas": int64(3), "enabled": true, "year": "2024"},
+			},
+		},
+		{
+			name:        "TypedDefault",
+			projectPath: "/base/config/3/2024",
+			wantFacts: map[string]interface{}{
+				"facts": map[string]interface{}{"replicas": int64(3), "enabled": false, "year": "2024"},
+			},
+		},
+		{
+			name:        "InvalidInt",
+			projectPath: "/base/config/three/enabled-true/2024",
+			wantErr:     true,
+		},
+		{
+			name:        "InvalidBool",
+			projectPath: "/base/config/3/enabled-yes/2024",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseProjectStructure("config/{{facts.replicas|int}}/enabled-{{facts.enabled|bool?=false}}/{{facts.year|string}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.MapPathToProject(tt.projectPath, HomeDirTesting)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapPathToProject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := p.Facts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(got, tt.wantFacts); diff != nil {
+				for _, d := range diff {
+					t.Logf("Facts() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
@@ -1,27 +1,204 @@
 package finder
 
 import (
+	"errors"
+	"fmt"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// ConfigFile is a config file found on one of the levels of the project structure.
+type ConfigFile struct {
+	Path  string
+	Level envfacts.VarMapping
+}
+
+// Options control how the config files of every level are found and ordered.
+type Options struct {
+	// Compat only uses the last segment of every glob, as in MatchGlobBases.
+	Compat bool
+	// Priorities of the globs, indexed by glob. Globs without a priority have priority 0.
+	Priorities map[string]int
+	// NumericPrefix orders files by the number their name starts with, as in `10-base.yaml`, before ordering them
+	// by path. Files without a numeric prefix come first.
+	NumericPrefix bool
+}
+
+// numericPrefix matches the number a file name starts with, as in `10-base.yaml`.
+var numericPrefix = regexp.MustCompile(`^([0-9]+)[-_]`)
+
 // FindConfigFiles finds all files named config.yaml that are found in the root.
 func FindConfigFiles(p envfacts.ProjectStructure, fileGlobs []string) (fileList []string, err error) {
-	fileList = make([]string, 0)
+	configFiles, err := FindLevelConfigFiles(p, fileGlobs, Options{})
+	if err != nil {
+		return nil, err
+	}
+	fileList = make([]string, len(configFiles))
+	for i, f := range configFiles {
+		fileList[i] = f.Path
+	}
+	return fileList, nil
+}
+
+// FindLevelConfigFiles finds the config files on every level, from the root down, along with the level they were found on.
+// Missing optional levels are skipped. The files of a level are ordered by the priority of the glob that matched them,
+// then by numeric prefix when enabled, then by path relative to the level directory. A file matched more than once,
+// by several globs or from several levels, is only kept at its last position: on the deepest level, with the highest
+// priority. Files are compared by real path, so symbolic links to the same file are found once.
+func FindLevelConfigFiles(p envfacts.ProjectStructure, fileGlobs []string, opts Options) (fileList []ConfigFile, err error) {
+	fileList = make([]ConfigFile, 0)
+
+	for level, v := range p.Levels() {
+		isNextLevel := func(name string) bool {
+			return p.IsNextLevel(level, name)
+		}
+		levelFiles := make([]orderedFile, 0)
+		for _, fileGlob := range fileGlobs {
+			var dirList []string
+			if opts.Compat {
+				dirList, err = MatchGlobBases([]string{fileGlob}, v.RealPath)
+			} else {
+				dirList, err = MatchGlobs([]string{fileGlob}, v.RealPath, isNextLevel)
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range dirList {
+				rel, err := filepath.Rel(v.RealPath, f)
+				if err != nil {
+					return nil, err
+				}
+				levelFiles = append(levelFiles, orderedFile{path: f, rel: filepath.ToSlash(rel), priority: opts.Priorities[fileGlob]})
+			}
+		}
+		sort.SliceStable(levelFiles, func(i, j int) bool {
+			return opts.less(levelFiles[i], levelFiles[j])
+		})
+		for _, f := range levelFiles {
+			fileList = append(fileList, ConfigFile{Path: f.path, Level: v})
+		}
+	}
+	return dedupConfigFiles(fileList)
+}
+
+// orderedFile is a config file of a level along with what it is ordered by.
+type orderedFile struct {
+	path string
+	// rel is the slash separated path relative to the level directory.
+	rel      string
+	priority int
+}
+
+// less orders the files of a level by priority, numeric prefix when enabled, and relative path.
+func (o Options) less(a, b orderedFile) bool {
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	if o.NumericPrefix {
+		if pa, pb := filePrefix(a.rel), filePrefix(b.rel); pa != pb {
+			return pa < pb
+		}
+	}
+	return a.rel < b.rel
+}
+
+// filePrefix returns the number the file name starts with, -1 when there is none.
+func filePrefix(rel string) int64 {
+	m := numericPrefix.FindStringSubmatch(path.Base(rel))
+	if m == nil {
+		return -1
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
 
-	for _, v := range append([]envfacts.VarMapping{p.Root}, p.Vars...) {
-		dirList, err := MatchGlobs(fileGlobs, v.RealPath)
+// dedupConfigFiles only keeps the last occurrence of every file, comparing them by real path.
+func dedupConfigFiles(configFiles []ConfigFile) ([]ConfigFile, error) {
+	seen := make(map[string]bool, len(configFiles))
+	kept := make([]ConfigFile, 0, len(configFiles))
+	for i := len(configFiles) - 1; i >= 0; i-- {
+		realPath, err := filepath.EvalSymlinks(configFiles[i].Path)
 		if err != nil {
 			return nil, err
 		}
-		fileList = append(fileList, dirList...)
+		if seen[realPath] {
+			continue
+		}
+		seen[realPath] = true
+		kept = append(kept, configFiles[i])
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept, nil
+}
+
+// FindLevelFactsFiles finds the facts file named name on every level, from the root down, along with the level it
+// was found on. Missing optional levels are skipped.
+func FindLevelFactsFiles(p envfacts.ProjectStructure, name string) (fileList []ConfigFile, err error) {
+	fileList = make([]ConfigFile, 0)
+
+	for _, v := range p.Levels() {
+		f := filepath.Join(v.RealPath, name)
+		info, err := os.Stat(f)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("facts file %q is a directory", f)
+		}
+		fileList = append(fileList, ConfigFile{Path: f, Level: v})
 	}
 	return fileList, nil
 }
 
 // MatchGlobs finds any file paths that matches any of the list of globs in `dirPath`.
-// The glob patterns are formed by joining `dirPath` with each of the globs in `fileGlobs`.
-func MatchGlobs(fileGlobs []string, dirPath string) (matches []string, err error) {
+// The globs are relative to `dirPath`, can hold subdirectories (`conf.d/*.yaml`) and `**` for any number of
+// directories. The matches of every glob are sorted.
+// Globs whose first segment is a pattern do not descend into the subdirectories of `dirPath` for which isNextLevel
+// is true, the directories of the lower levels of the hierarchy, so that `**` does not reach into other branches of
+// the hierarchy. A nil isNextLevel descends into every subdirectory.
+func MatchGlobs(fileGlobs []string, dirPath string, isNextLevel func(name string) bool) (matches []string, err error) {
+	matches = make([]string, 0)
+	dirFS := os.DirFS(dirPath)
+	for _, fileGlob := range fileGlobs {
+		if err := ValidateGlob(fileGlob); err != nil {
+			return matches, err
+		}
+		pattern := path.Clean(filepath.ToSlash(fileGlob))
+		var fsys fs.FS = dirFS
+		if base, _ := doublestar.SplitPattern(pattern); base == "." && isNextLevel != nil {
+			fsys = levelFS{FS: dirFS, isNextLevel: isNextLevel}
+		}
+		results, err := doublestar.Glob(fsys, pattern, doublestar.WithFilesOnly())
+		if err != nil {
+			return matches, err
+		}
+		sort.Strings(results)
+		for _, result := range results {
+			matches = append(matches, filepath.Join(dirPath, filepath.FromSlash(result)))
+		}
+	}
+	return matches, nil
+}
+
+// MatchGlobBases finds any file paths that matches any of the list of globs in `dirPath`, only using the last
+// segment of every glob. This is how globs were matched before they supported subdirectories.
+func MatchGlobBases(fileGlobs []string, dirPath string) (matches []string, err error) {
 	matches = make([]string, 0)
 	for _, fileGlob := range fileGlobs {
 		base := filepath.Base(fileGlob)
@@ -33,3 +210,79 @@ func MatchGlobs(fileGlobs []string, dirPath string) (matches []string, err error
 	}
 	return matches, nil
 }
+
+// ValidateGlob checks that the glob is a valid pattern relative to the level directories.
+func ValidateGlob(fileGlob string) error {
+	slashed := filepath.ToSlash(fileGlob)
+	clean := path.Clean(slashed)
+	switch {
+	case fileGlob == "":
+		return fmt.Errorf("config glob can not be empty")
+	case path.IsAbs(slashed) || filepath.IsAbs(fileGlob):
+		return fmt.Errorf("config glob %q needs to be relative to the level directories", fileGlob)
+	case clean == ".." || strings.HasPrefix(clean, "../"):
+		return fmt.Errorf("config glob %q can not match files outside of the level directories", fileGlob)
+	case !doublestar.ValidatePattern(clean):
+		return fmt.Errorf("config glob %q is not a valid pattern", fileGlob)
+	}
+	return nil
+}
+
+// IgnoredGlobComponents returns the directory components of the glob that MatchGlobBases ignores, empty when the
+// glob is a single segment.
+func IgnoredGlobComponents(fileGlob string) string {
+	if dir := filepath.Dir(fileGlob); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// levelFS hides the subdirectories of a level directory that are the directories of the lower levels of the hierarchy.
+type levelFS struct {
+	fs.FS
+	isNextLevel func(name string) bool
+}
+
+// hidden checks if name is, or is below, a hidden subdirectory.
+func (l levelFS) hidden(name string) bool {
+	first, rest, below := strings.Cut(name, "/")
+	if first == "." || !l.isNextLevel(first) {
+		return false
+	}
+	if below && rest != "" {
+		return true
+	}
+	info, err := fs.Stat(l.FS, first)
+	return err == nil && info.IsDir()
+}
+
+func (l levelFS) Open(name string) (fs.File, error) {
+	if l.hidden(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return l.FS.Open(name)
+}
+
+func (l levelFS) Stat(name string) (fs.FileInfo, error) {
+	if l.hidden(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.Stat(l.FS, name)
+}
+
+func (l levelFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if l.hidden(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries, err := fs.ReadDir(l.FS, name)
+	if err != nil || name != "." {
+		return entries, err
+	}
+	kept := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !l.hidden(entry.Name()) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,287 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/stefan-kiss/terraform-provider-config-merger/pkg/envfacts"
+)
+
+// testTree creates the files in a temporary directory and returns it.
+func testTree(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("key: value\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMatchGlobs(t *testing.T) {
+	dir := testTree(t,
+		"config.yaml",
+		"conf.d/b.yaml",
+		"conf.d/a.yaml",
+		"conf.d/notes.txt",
+		"conf.d/extra/c.yaml",
+		"production/config.yaml",
+	)
+	tests := []struct {
+		name      string
+		fileGlobs []string
+		compat    bool
+		want      []string
+	}{
+		{
+			name:      "FileName",
+			fileGlobs: []string{"config.yaml"},
+			want:      []string{"config.yaml"},
+		},
+		{
+			name:      "Subdirectory",
+			fileGlobs: []string{"conf.d/*.yaml"},
+			want:      []string{"conf.d/a.yaml", "conf.d/b.yaml"},
+		},
+		{
+			name:      "DoubleStar",
+			fileGlobs: []string{"conf.d/**/*.yaml"},
+			want:      []string{"conf.d/a.yaml", "conf.d/b.yaml", "conf.d/extra/c.yaml"},
+		},
+		{
+			name:      "DoubleStarMatchesLowerLevels",
+			fileGlobs: []string{"**/config.yaml"},
+			want:      []string{"config.yaml", "production/config.yaml"},
+		},
+		{
+			name:      "Compat",
+			fileGlobs: []string{"conf.d/*.yaml"},
+			compat:    true,
+			want:      []string{"config.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := MatchGlobs(tt.fileGlobs, dir, nil)
+			if tt.compat {
+				matches, err = MatchGlobBases(tt.fileGlobs, dir)
+			}
+			if err != nil {
+				t.Errorf("MatchGlobs() error = %v", err)
+				return
+			}
+			got := make([]string, len(matches))
+			for i, m := range matches {
+				got[i], _ = filepath.Rel(dir, m)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("MatchGlobs() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	for _, glob := range []string{"config.yaml", "conf.d/*.yaml", "**/*.yaml", "./conf.d/{a,b}.yaml"} {
+		if err := ValidateGlob(glob); err != nil {
+			t.Errorf("ValidateGlob(%q) error = %v", glob, err)
+		}
+	}
+	for _, glob := range []string{"", "/etc/config.yaml", "../config.yaml", "conf.d/../../config.yaml", "conf.d/[a.yaml"} {
+		if err := ValidateGlob(glob); err == nil {
+			t.Errorf("ValidateGlob(%q) expected an error", glob)
+		}
+	}
+}
+
+func TestIgnoredGlobComponents(t *testing.T) {
+	for glob, want := range map[string]string{"config.yaml": "", "conf.d/*.yaml": "conf.d", "**/extra/*.yaml": "**/extra"} {
+		if got := IgnoredGlobComponents(glob); got != want {
+			t.Errorf("IgnoredGlobComponents(%q) = %q, want %q", glob, got, want)
+		}
+	}
+}
+
+func TestFindLevelConfigFiles(t *testing.T) {
+	dir := testTree(t,
+		"config/config.yaml",
+		"config/production/config.yaml",
+		"config/production/b.override.yaml",
+		"config/production/a.override.yaml",
+		"config/production/conf.d/10-team.yaml",
+		"config/production/conf.d/9-base.yaml",
+		"config/production/conf.d/common.yaml",
+	)
+	if err := os.Symlink("config.yaml", filepath.Join(dir, "config/production/linked.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		fileGlobs []string
+		opts      Options
+		want      []string
+	}{
+		{
+			name:      "Lexical",
+			fileGlobs: []string{"*.override.yaml", "config.yaml", "conf.d/*.yaml"},
+			want: []string{
+				"config/config.yaml",
+				"config/production/a.override.yaml",
+				"config/production/b.override.yaml",
+				"config/production/conf.d/10-team.yaml",
+				"config/production/conf.d/9-base.yaml",
+				"config/production/conf.d/common.yaml",
+				"config/production/config.yaml",
+			},
+		},
+		{
+			name:      "Priorities",
+			fileGlobs: []string{"*.override.yaml", "config.yaml", "conf.d/*.yaml"},
+			opts:      Options{Priorities: map[string]int{"*.override.yaml": 10, "config.yaml": -1}},
+			want: []string{
+				"config/config.yaml",
+				"config/production/config.yaml",
+				"config/production/conf.d/10-team.yaml",
+				"config/production/conf.d/9-base.yaml",
+				"config/production/conf.d/common.yaml",
+				"config/production/a.override.yaml",
+				"config/production/b.override.yaml",
+			},
+		},
+		{
+			name:      "NumericPrefix",
+			fileGlobs: []string{"conf.d/*.yaml"},
+			opts:      Options{NumericPrefix: true},
+			want: []string{
+				"config/production/conf.d/common.yaml",
+				"config/production/conf.d/9-base.yaml",
+				"config/production/conf.d/10-team.yaml",
+			},
+		},
+		{
+			name:      "MatchedTwice",
+			fileGlobs: []string{"*.yaml", "config.yaml"},
+			opts:      Options{Priorities: map[string]int{"config.yaml": 1}},
+			want: []string{
+				"config/config.yaml",
+				"config/production/a.override.yaml",
+				"config/production/b.override.yaml",
+				"config/production/config.yaml",
+			},
+		},
+		{
+			name:      "MatchedFromSeveralLevels",
+			fileGlobs: []string{"config.yaml", "production/config.yaml"},
+			want: []string{
+				"config/config.yaml",
+				"config/production/config.yaml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := envfacts.ParseProjectStructure("config/{{facts.environment}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.MapPathToProject(filepath.Join(dir, "config/production"), os.UserHomeDir); err != nil {
+				t.Fatal(err)
+			}
+			configFiles, err := FindLevelConfigFiles(p, tt.fileGlobs, tt.opts)
+			if err != nil {
+				t.Errorf("FindLevelConfigFiles() error = %v", err)
+				return
+			}
+			got := make([]string, len(configFiles))
+			for i, f := range configFiles {
+				got[i], _ = filepath.Rel(dir, f.Path)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("FindLevelConfigFiles() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestFindLevelConfigFilesSiblings(t *testing.T) {
+	dir := testTree(t,
+		"config/config.yaml",
+		"config/conf.d/common.yaml",
+		"config/prod/config.yaml",
+		"config/prod/app/config.yaml",
+		"config/prod/app/conf.d/extra/config.yaml",
+		"config/prod/other/config.yaml",
+		"config/staging/config.yaml",
+		"config/staging/app/config.yaml",
+	)
+	tests := []struct {
+		name      string
+		fileGlobs []string
+		want      []string
+	}{
+		{
+			name:      "DoubleStar",
+			fileGlobs: []string{"**/config.yaml"},
+			want: []string{
+				"config/config.yaml",
+				"config/prod/config.yaml",
+				"config/prod/app/conf.d/extra/config.yaml",
+				"config/prod/app/config.yaml",
+			},
+		},
+		{
+			name:      "Wildcard",
+			fileGlobs: []string{"*/config.yaml", "config.yaml"},
+			want: []string{
+				"config/config.yaml",
+				"config/prod/config.yaml",
+				"config/prod/app/config.yaml",
+			},
+		},
+		{
+			name:      "LiteralDirectory",
+			fileGlobs: []string{"conf.d/**/*.yaml"},
+			want: []string{
+				"config/conf.d/common.yaml",
+				"config/prod/app/conf.d/extra/config.yaml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := envfacts.ParseProjectStructure("config/{{env}}/{{app}}")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.MapPathToProject(filepath.Join(dir, "config/prod/app"), os.UserHomeDir); err != nil {
+				t.Fatal(err)
+			}
+			configFiles, err := FindLevelConfigFiles(p, tt.fileGlobs, Options{})
+			if err != nil {
+				t.Errorf("FindLevelConfigFiles() error = %v", err)
+				return
+			}
+			got := make([]string, len(configFiles))
+			for i, f := range configFiles {
+				got[i], _ = filepath.Rel(dir, f.Path)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("FindLevelConfigFiles() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by Encode.
+const (
+	FormatYAML          = "yaml"
+	FormatJSON          = "json"
+	FormatCanonicalJSON = "canonical-json"
+)
+
+// OutputFormats lists the output formats supported by Encode.
+var OutputFormats = []string{FormatYAML, FormatJSON, FormatCanonicalJSON}
+
+// ValidateOutputFormat checks that the format is one of OutputFormats.
+func ValidateOutputFormat(format string) error {
+	for _, f := range OutputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, expected one of: %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// Encode encodes the tree in the given format:
+//   - yaml: yaml with sorted keys, as produced by gopkg.in/yaml.v3
+//   - json: json with sorted keys, indented with two spaces
+//   - canonical-json: json with sorted keys, no insignificant whitespace and numbers in their shortest form,
+//     suitable for hashing
+func Encode(tree interface{}, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(tree)
+	case FormatJSON:
+		converted, err := StringKeys(tree)
+		if err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(converted); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatCanonicalJSON:
+		converted, err := StringKeys(tree)
+		if err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		if err := writeCanonicalJSON(buf, converted, ""); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, ValidateOutputFormat(format)
+	}
+}
+
+// StringKeys returns a copy of the tree where the interface keyed maps produced by spruce are converted to string
+// keyed maps. Keys that are not strings are converted to their string representation; an error is returned when two
+// keys of the same map end up with the same representation.
+func StringKeys(tree interface{}) (interface{}, error) {
+	return stringKeys(tree, "")
+}
+
+func stringKeys(node interface{}, p string) (interface{}, error) {
+	switch v := node.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			name := fmt.Sprintf("%v", key)
+			if _, exists := out[name]; exists {
+				return nil, fmt.Errorf("key %q at %q is defined more than once after converting keys to strings", name, displayPath(p))
+			}
+			converted, err := stringKeys(value, joinPath(p, name))
+			if err != nil {
+				return nil, err
+			}
+			out[name] = converted
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			converted, err := stringKeys(value, joinPath(p, key))
+			if err != nil {
+				return nil, err
+			}
+			out[key] = converted
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			converted, err := stringKeys(value, joinPath(p, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = converted
+		}
+		return out, nil
+	default:
+		return node, nil
+	}
+}
+
+// displayPath returns the key path used in error messages, "." being the root of the document.
+func displayPath(p string) string {
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, node interface{}, p string) error {
+	switch v := node.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		return writeJSONString(buf, v)
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+	case float32:
+		return writeCanonicalFloat(buf, float64(v), p)
+	case float64:
+		return writeCanonicalFloat(buf, v, p)
+	case *big.Int:
+		buf.WriteString(v.String())
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONString(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[key], joinPath(p, key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, value := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, value, joinPath(p, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		return fmt.Errorf("unsupported value of type %T at %q", node, displayPath(p))
+	}
+	return nil
+}
+
+// writeCanonicalFloat writes the number in its shortest form, without exponent between 1e-6 and 1e21,
+// as ECMAScript does.
+func writeCanonicalFloat(buf *bytes.Buffer, f float64, p string) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("value %v at %q can not be represented in json", f, displayPath(p))
+	}
+	if f == 0 {
+		buf.WriteString("0")
+		return nil
+	}
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		return nil
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	// ECMAScript does not pad the exponent: 1e-07 becomes 1e-7
+	mantissa, exponent, _ := strings.Cut(s, "e")
+	sign := exponent[:1]
+	exponent = strings.TrimLeft(exponent[1:], "0")
+	buf.WriteString(mantissa + "e" + sign + exponent)
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	// json.Encoder terminates every value with a newline
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
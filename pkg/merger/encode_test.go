@@ -0,0 +1,94 @@
+package merger
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestEncode(t *testing.T) {
+	tree := map[interface{}]interface{}{
+		"b":    "<value>",
+		"a":    []interface{}{1, 1.5, true, nil},
+		1:      "int key",
+		"nest": map[interface{}]interface{}{"z": int64(-3), "y": uint64(math.MaxUint64)},
+	}
+	tests := []struct {
+		name    string
+		tree    interface{}
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "YAML",
+			tree:   map[interface{}]interface{}{"b": "value", "a": 1},
+			format: FormatYAML,
+			want:   "a: 1\nb: value\n",
+		},
+		{
+			name:   "JSON",
+			tree:   map[interface{}]interface{}{"b": "value", "a": []interface{}{1}},
+			format: FormatJSON,
+			want:   "{\n  \"a\": [\n    1\n  ],\n  \"b\": \"value\"\n}\n",
+		},
+		{
+			name:   "CanonicalJSON",
+			tree:   tree,
+			format: FormatCanonicalJSON,
+			want:   `{"1":"int key","a":[1,1.5,true,null],"b":"<value>","nest":{"y":18446744073709551615,"z":-3}}`,
+		},
+		{
+			name:   "CanonicalJSONFloats",
+			tree:   []interface{}{1e21, 1e-7, 0.000001, 100.0, -0.0},
+			format: FormatCanonicalJSON,
+			want:   `[1e+21,1e-7,0.000001,100,0]`,
+		},
+		{
+			name:    "CanonicalJSONNaN",
+			tree:    []interface{}{math.NaN()},
+			format:  FormatCanonicalJSON,
+			wantErr: true,
+		},
+		{
+			name:    "DuplicateKeys",
+			tree:    map[interface{}]interface{}{1: "int", "1": "string"},
+			format:  FormatJSON,
+			wantErr: true,
+		},
+		{
+			name:    "UnknownFormat",
+			tree:    map[interface{}]interface{}{},
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.tree, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("Encode() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringKeys(t *testing.T) {
+	got, err := StringKeys(map[interface{}]interface{}{
+		true: []interface{}{map[interface{}]interface{}{2: "two"}},
+	})
+	if err != nil {
+		t.Fatalf("StringKeys() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"true": []interface{}{map[string]interface{}{"2": "two"}},
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("StringKeys() differences: %v", diff)
+	}
+}
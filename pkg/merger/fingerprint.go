@@ -0,0 +1,73 @@
+package merger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// fingerprintOpts holds the merge options that change the merged result, in a stable order.
+type fingerprintOpts struct {
+	SkipEval       bool
+	Prune          []string
+	CherryPick     []string
+	FallbackAppend bool
+	EnableGoPatch  bool
+	MultiDoc       bool
+}
+
+// Fingerprint computes a deterministic SHA-256 over the ordered list of files, their content and the merge options.
+// The files are read in the process, so Fingerprint returns them with their content buffered, ready to be merged.
+func Fingerprint(files []YamlFile, options MergeOpts) (string, []YamlFile, error) {
+	h := sha256.New()
+	buffered := make([]YamlFile, len(files))
+
+	for i, file := range files {
+		data, err := readFile(&file)
+		if file.Reader != nil {
+			_ = file.Reader.Close()
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		// length prefixes keep the boundaries between paths and contents unambiguous
+		_, _ = fmt.Fprintf(h, "file %d %s\n%d\n", len(file.Path), file.Path, len(data))
+		_, _ = h.Write(data)
+
+		file.Reader = io.NopCloser(bytes.NewReader(data))
+		buffered[i] = file
+	}
+
+	opts, err := json.Marshal(fingerprintOpts{
+		SkipEval:       options.SkipEval,
+		Prune:          nilIfEmpty(options.Prune),
+		CherryPick:     nilIfEmpty(options.CherryPick),
+		FallbackAppend: options.FallbackAppend,
+		EnableGoPatch:  options.EnableGoPatch,
+		MultiDoc:       options.MultiDoc,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	_, _ = fmt.Fprintf(h, "options %d\n", len(opts))
+	_, _ = h.Write(opts)
+
+	return hex.EncodeToString(h.Sum(nil)), buffered, nil
+}
+
+// nilIfEmpty makes empty and nil lists fingerprint the same way.
+func nilIfEmpty(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// ContentHash returns the SHA-256 of the given content.
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
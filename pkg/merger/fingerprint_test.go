@@ -0,0 +1,70 @@
+package merger
+
+import (
+	"io"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	base, _, err := Fingerprint(yamlFilesTesting("a: 1\n", "b: 2\n"), MergeOpts{})
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		files    []YamlFile
+		options  MergeOpts
+		wantSame bool
+	}{
+		{
+			name:     "SameInputs",
+			files:    yamlFilesTesting("a: 1\n", "b: 2\n"),
+			wantSame: true,
+		},
+		{
+			name:     "EmptyPruneList",
+			files:    yamlFilesTesting("a: 1\n", "b: 2\n"),
+			options:  MergeOpts{Prune: []string{}},
+			wantSame: true,
+		},
+		{
+			name:  "DifferentContent",
+			files: yamlFilesTesting("a: 1\n", "b: 3\n"),
+		},
+		{
+			name:  "ContentMovedBetweenFiles",
+			files: yamlFilesTesting("a: 1\nb: 2\n", ""),
+		},
+		{
+			name:    "DifferentOptions",
+			files:   yamlFilesTesting("a: 1\n", "b: 2\n"),
+			options: MergeOpts{Prune: []string{"a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := Fingerprint(tt.files, tt.options)
+			if err != nil {
+				t.Fatalf("Fingerprint() error = %v", err)
+			}
+			if (got == base) != tt.wantSame {
+				t.Errorf("Fingerprint() got = %v, base %v, wantSame %v", got, base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestFingerprintKeepsContent(t *testing.T) {
+	_, files, err := Fingerprint(yamlFilesTesting("a: 1\n"), MergeOpts{})
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	data, err := io.ReadAll(files[0].Reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(data) != "a: 1\n" {
+		t.Errorf("Fingerprint() file content = %q, want %q", data, "a: 1\n")
+	}
+}
@@ -1,17 +1,24 @@
 package merger
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/cppforlife/go-patch/patch"
 	"github.com/geofffranks/simpleyaml"
 	"github.com/geofffranks/spruce"
 	"github.com/geofffranks/yaml"
 	log "github.com/sirupsen/logrus"
 	"github.com/starkandwayne/goutils/ansi"
+	"github.com/starkandwayne/goutils/tree"
 	"github.com/voxelbrain/goptions"
 	"io"
 	"os"
+	"sync"
 )
 
+// mergeLock serializes merges, as spruce keeps the keys to prune and the paths to sort in package level variables.
+var mergeLock sync.Mutex
+
 type RootIsArrayError struct {
 	msg string
 }
@@ -23,6 +30,8 @@ func (r RootIsArrayError) Error() string {
 type YamlFile struct {
 	Path   string
 	Reader io.ReadCloser
+	// Level is the hierarchy level the file was found on, reported in the sources of the merged values.
+	Level string
 }
 
 func LoadYamlFile(file string) (YamlFile, error) {
@@ -50,6 +59,19 @@ type MergeOpts struct {
 	Files          goptions.Remainder `goptions:"description='List of files to merge. To read STDIN, specify a filename of \\'-\\'.'"`
 }
 
+// ValidateKeyPaths checks that every path is a valid spruce key path, as expected by the prune and cherry pick options.
+func ValidateKeyPaths(paths []string) error {
+	for _, p := range paths {
+		if p == "" {
+			return fmt.Errorf("key path can not be empty")
+		}
+		if _, err := tree.ParseCursor(p); err != nil {
+			return fmt.Errorf("invalid key path %q: %s", p, err)
+		}
+	}
+	return nil
+}
+
 func isArrayError(err error) bool {
 	_, ok := err.(RootIsArrayError)
 	return ok
@@ -120,7 +142,112 @@ func parseGoPatch(data []byte) (patch.Ops, error) {
 	return ops, nil
 }
 
+// mergeDoc merges a single yaml document into root, returning the new root and the paths of the leaves the
+// document defines. When go-patch is enabled, documents whose root is an array are applied as go-patch operations.
+func mergeDoc(m *spruce.Merger, root map[interface{}]interface{}, docName string, data []byte, options MergeOpts) (map[interface{}]interface{}, []string, error) {
+	var docPaths []string
+	doc, err := parseYAML(data)
+	if err != nil {
+		if isArrayError(err) && options.EnableGoPatch {
+			log.Debugf("Detected root of document as an array. Attempting go-patch parsing")
+			ops, err := parseGoPatch(data)
+			if err != nil {
+				return nil, nil, ansi.Errorf("@m{%s}: @R{%s}\n", docName, err.Error())
+			}
+			newObj, err := ops.Apply(root)
+			if err != nil {
+				return nil, nil, ansi.Errorf("@m{%s}: @R{%s}\n", docName, err.Error())
+			}
+			if newRoot, ok := newObj.(map[interface{}]interface{}); !ok {
+				return nil, nil, ansi.Errorf("@m{%s}: @R{Unable to convert go-patch output into a hash/map for further merging|\n", docName)
+			} else {
+				root = newRoot
+			}
+		} else {
+			return nil, nil, ansi.Errorf("@m{%s}: @R{%s}\n", docName, err.Error())
+		}
+	} else {
+		docPaths = mapLeafPaths(doc)
+		// this is ignored in spruce original code also. TBD if we need to treat it as an error.
+		_ = m.Merge(root, doc)
+
+	}
+	tmpYaml, _ := yaml.Marshal(root) // we don't care about errors for debugging
+	log.Debugf("Current data after processing '%s':\n%s", docName, tmpYaml)
+	return root, docPaths, nil
+}
+
+// SplitDocuments splits a multi-document yaml stream into its documents.
+// Documents are separated by a `---` marker, and optionally ended by a `...` marker, at the start of a line.
+// Content before the first marker is only considered a document if it holds anything other than comments and directives.
+func SplitDocuments(data []byte) [][]byte {
+	docs := make([][]byte, 0)
+	current := make([]byte, 0, len(data))
+	started := false
+	flush := func() {
+		if started || !isBlankDocument(current) {
+			docs = append(docs, current)
+		}
+		current = make([]byte, 0)
+		started = true
+	}
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		trimmed := bytes.TrimRight(line, "\r\n")
+		switch {
+		case isDocumentMarker(trimmed, "---"):
+			flush()
+			// content following the marker on the same line belongs to the new document
+			current = append(current, bytes.TrimLeft(trimmed[3:], " \t")...)
+			current = append(current, '\n')
+		case isDocumentMarker(trimmed, "..."):
+			flush()
+			started = false
+		default:
+			current = append(current, line...)
+		}
+	}
+	if started || !isBlankDocument(current) {
+		docs = append(docs, current)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, []byte{})
+	}
+	return docs
+}
+
+// isDocumentMarker checks if the line is the given document marker, optionally followed by whitespace and content.
+func isDocumentMarker(line []byte, marker string) bool {
+	if !bytes.HasPrefix(line, []byte(marker)) {
+		return false
+	}
+	return len(line) == len(marker) || line[len(marker)] == ' ' || line[len(marker)] == '\t'
+}
+
+// isBlankDocument checks if the document only holds whitespace, comments and directives.
+func isBlankDocument(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' && line[0] != '%' {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeAllDocs merges the files in order and evaluates the result.
 func MergeAllDocs(files []YamlFile, options MergeOpts) (*spruce.Evaluator, error) {
+	ev, _, err := MergeAllDocsWithSources(files, options)
+	return ev, err
+}
+
+// MergeAllDocsWithSources merges the files in order and evaluates the result, also returning the source of
+// every leaf in the result.
+func MergeAllDocsWithSources(files []YamlFile, options MergeOpts) (*spruce.Evaluator, Sources, error) {
+	mergeLock.Lock()
+	defer mergeLock.Unlock()
+
+	tracker := newSourceTracker()
 	m := &spruce.Merger{AppendByDefault: options.FallbackAppend}
 	root := make(map[interface{}]interface{})
 
@@ -129,36 +256,50 @@ func MergeAllDocs(files []YamlFile, options MergeOpts) (*spruce.Evaluator, error
 
 		data, err := readFile(&file)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		doc, err := parseYAML(data)
-		if err != nil {
-			if isArrayError(err) && options.EnableGoPatch {
-				log.Debugf("Detected root of document as an array. Attempting go-patch parsing")
-				ops, err := parseGoPatch(data)
-				if err != nil {
-					return nil, ansi.Errorf("@m{%s}: @R{%s}\n", file.Path, err.Error())
-				}
-				newObj, err := ops.Apply(root)
-				if err != nil {
-					return nil, ansi.Errorf("@m{%s}: @R{%s}\n", file.Path, err.Error())
-				}
-				if newRoot, ok := newObj.(map[interface{}]interface{}); !ok {
-					return nil, ansi.Errorf("@m{%s}: @R{Unable to convert go-patch output into a hash/map for further merging|\n", file.Path)
-				} else {
-					root = newRoot
-				}
-			} else {
-				return nil, ansi.Errorf("@m{%s}: @R{%s}\n", file.Path, err.Error())
-			}
-		} else {
-			// this is ignored in spruce original code also. TBD if we need to treat it as an error.
-			_ = m.Merge(root, doc)
+		docs := [][]byte{data}
+		if options.MultiDoc {
+			docs = SplitDocuments(data)
+		}
 
+		for idx, docData := range docs {
+			docName := file.Path
+			if len(docs) > 1 {
+				docName = fmt.Sprintf("%s (document %d)", file.Path, idx+1)
+			}
+			var docPaths []string
+			root, docPaths, err = mergeDoc(m, root, docName, docData, options)
+			if err != nil {
+				return nil, nil, err
+			}
+			tracker.track(root, docPaths, Source{File: file.Path, Level: file.Level})
 		}
-		tmpYaml, _ := yaml.Marshal(root) // we don't care about errors for debugging
-		log.Debugf("Current data after processing '%s':\n%s", file.Path, tmpYaml)
+	}
+
+	if m.Error() != nil {
+		return nil, nil, m.Error()
+	}
+
+	ev := &spruce.Evaluator{Tree: root, SkipEval: options.SkipEval}
+	err := ev.Run(options.Prune, options.CherryPick)
+	tracker.evaluated(ev.Tree)
+	return ev, tracker.sources, err
+}
+
+// MergeTrees merges the trees in order and evaluates the result, the same way MergeAllDocs merges the documents
+// of the files. The trees may be modified in the process.
+func MergeTrees(trees []map[interface{}]interface{}, options MergeOpts) (*spruce.Evaluator, error) {
+	mergeLock.Lock()
+	defer mergeLock.Unlock()
+
+	m := &spruce.Merger{AppendByDefault: options.FallbackAppend}
+	root := make(map[interface{}]interface{})
+
+	for _, tree := range trees {
+		// this is ignored in spruce original code also, errors are collected by the merger.
+		_ = m.Merge(root, tree)
 	}
 
 	if m.Error() != nil {
@@ -0,0 +1,319 @@
+package merger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// yamlFilesTesting returns in memory yaml files named after their index.
+func yamlFilesTesting(docs ...string) []YamlFile {
+	files := make([]YamlFile, len(docs))
+	for i, doc := range docs {
+		files[i] = YamlFile{
+			Path:   fmt.Sprintf("file%d.yaml", i),
+			Reader: io.NopCloser(strings.NewReader(doc)),
+		}
+	}
+	return files
+}
+
+func TestMergeAllDocs(t *testing.T) {
+	base := `
+meta:
+  name: base
+list:
+- name: a
+  value: 1
+value: (( grab meta.name ))
+`
+	override := `
+list:
+- value: 2
+`
+	tests := []struct {
+		name    string
+		docs    []string
+		options MergeOpts
+		want    map[interface{}]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Defaults",
+			docs:    []string{base, override},
+			options: MergeOpts{},
+			want: map[interface{}]interface{}{
+				"meta":  map[interface{}]interface{}{"name": "base"},
+				"list":  []interface{}{map[interface{}]interface{}{"name": "a", "value": 2}},
+				"value": "base",
+			},
+		},
+		{
+			name:    "SkipEval",
+			docs:    []string{base},
+			options: MergeOpts{SkipEval: true},
+			want: map[interface{}]interface{}{
+				"meta":  map[interface{}]interface{}{"name": "base"},
+				"list":  []interface{}{map[interface{}]interface{}{"name": "a", "value": 1}},
+				"value": "(( grab meta.name ))",
+			},
+		},
+		{
+			name:    "Prune",
+			docs:    []string{base},
+			options: MergeOpts{Prune: []string{"meta", "list"}},
+			want: map[interface{}]interface{}{
+				"value": "base",
+			},
+		},
+		{
+			name:    "CherryPick",
+			docs:    []string{base},
+			options: MergeOpts{CherryPick: []string{"value"}},
+			want: map[interface{}]interface{}{
+				"value": "base",
+			},
+		},
+		{
+			name:    "FallbackAppend",
+			docs:    []string{base, override},
+			options: MergeOpts{FallbackAppend: true},
+			want: map[interface{}]interface{}{
+				"meta": map[interface{}]interface{}{"name": "base"},
+				"list": []interface{}{
+					map[interface{}]interface{}{"name": "a", "value": 1},
+					map[interface{}]interface{}{"value": 2},
+				},
+				"value": "base",
+			},
+		},
+		{
+			name:    "MultiDocDisabled",
+			docs:    []string{base, "value: first\n---\nvalue: second\n"},
+			options: MergeOpts{},
+			want: map[interface{}]interface{}{
+				"meta":  map[interface{}]interface{}{"name": "base"},
+				"list":  []interface{}{map[interface{}]interface{}{"name": "a", "value": 1}},
+				"value": "first",
+			},
+		},
+		{
+			name:    "MultiDoc",
+			docs:    []string{base, "value: first\n---\nvalue: second\n"},
+			options: MergeOpts{MultiDoc: true},
+			want: map[interface{}]interface{}{
+				"meta":  map[interface{}]interface{}{"name": "base"},
+				"list":  []interface{}{map[interface{}]interface{}{"name": "a", "value": 1}},
+				"value": "second",
+			},
+		},
+		{
+			name:    "MultiDocGoPatch",
+			docs:    []string{base + "---\n- type: remove\n  path: /list\n"},
+			options: MergeOpts{MultiDoc: true, EnableGoPatch: true},
+			want: map[interface{}]interface{}{
+				"meta":  map[interface{}]interface{}{"name": "base"},
+				"value": "base",
+			},
+		},
+		{
+			name:    "MultiDocError",
+			docs:    []string{"value: first\n---\n- not a map\n"},
+			options: MergeOpts{MultiDoc: true},
+			wantErr: true,
+		},
+		{
+			name:    "GoPatchDisabled",
+			docs:    []string{base, "- type: remove\n  path: /meta\n"},
+			options: MergeOpts{},
+			wantErr: true,
+		},
+		{
+			name:    "GoPatch",
+			docs:    []string{base, "- type: replace\n  path: /meta/name\n  value: patched\n"},
+			options: MergeOpts{EnableGoPatch: true},
+			want: map[interface{}]interface{}{
+				"meta":  map[interface{}]interface{}{"name": "patched"},
+				"list":  []interface{}{map[interface{}]interface{}{"name": "a", "value": 1}},
+				"value": "patched",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := MergeAllDocs(yamlFilesTesting(tt.docs...), tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MergeAllDocs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := deep.Equal(ev.Tree, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("MergeAllDocs() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestMergeAllDocsMultiDocErrorNamesDocument(t *testing.T) {
+	_, err := MergeAllDocs(yamlFilesTesting("value: first\n---\n- not a map\n"), MergeOpts{MultiDoc: true})
+	if err == nil {
+		t.Fatal("MergeAllDocs() expected an error")
+	}
+	if !strings.Contains(err.Error(), "file0.yaml (document 2)") {
+		t.Errorf("MergeAllDocs() error = %q, want it to name the file and the document", err)
+	}
+}
+
+func TestMergeAllDocsWithSources(t *testing.T) {
+	files := yamlFilesTesting(
+		"a: 1\nb: 1\nc: 1\nlist:\n- x\n",
+		"b: 1\nc: 2\nd: (( grab c ))\ne: (( grab nested ))\nnested:\n  key: value\n",
+	)
+	files[0].Level = "root"
+	files[1].Level = "leaf"
+
+	_, sources, err := MergeAllDocsWithSources(files, MergeOpts{})
+	if err != nil {
+		t.Fatalf("MergeAllDocsWithSources() error = %v", err)
+	}
+	want := Sources{
+		"a":          {File: "file0.yaml", Level: "root"},
+		"b":          {File: "file1.yaml", Level: "leaf"},
+		"c":          {File: "file1.yaml", Level: "leaf"},
+		"list.0":     {File: "file0.yaml", Level: "root"},
+		"d":          {File: "file1.yaml", Level: "leaf", Operator: true},
+		"e.key":      {File: "file1.yaml", Level: "leaf", Operator: true},
+		"nested.key": {File: "file1.yaml", Level: "leaf"},
+	}
+	if diff := deep.Equal(sources, want); diff != nil {
+		for _, d := range diff {
+			t.Logf("MergeAllDocsWithSources() differences between want and got: %v", d)
+		}
+		t.Fail()
+	}
+}
+
+func TestMergeTrees(t *testing.T) {
+	trees := []map[interface{}]interface{}{
+		{
+			"meta": map[interface{}]interface{}{"name": "base"},
+			"list": []interface{}{map[interface{}]interface{}{"name": "a", "value": 1}},
+		},
+		{
+			"list":  []interface{}{map[interface{}]interface{}{"name": "a", "other": 2}},
+			"value": "(( grab meta.name ))",
+		},
+	}
+	ev, err := MergeTrees(trees, MergeOpts{})
+	if err != nil {
+		t.Fatalf("MergeTrees() error = %v", err)
+	}
+	want := map[interface{}]interface{}{
+		"meta":  map[interface{}]interface{}{"name": "base"},
+		"list":  []interface{}{map[interface{}]interface{}{"name": "a", "value": 1, "other": 2}},
+		"value": "base",
+	}
+	if diff := deep.Equal(ev.Tree, want); diff != nil {
+		for _, d := range diff {
+			t.Logf("MergeTrees() differences between want and got: %v", d)
+		}
+		t.Fail()
+	}
+}
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "Empty",
+			data: "",
+			want: []string{""},
+		},
+		{
+			name: "SingleDocument",
+			data: "a: 1\n",
+			want: []string{"a: 1\n"},
+		},
+		{
+			name: "LeadingMarker",
+			data: "# comment\n---\na: 1\n",
+			want: []string{"\na: 1\n"},
+		},
+		{
+			name: "MultipleDocuments",
+			data: "a: 1\n---\nb: 2\n--- # second\nc: 3\n",
+			want: []string{"a: 1\n", "\nb: 2\n", "# second\nc: 3\n"},
+		},
+		{
+			name: "DocumentEndMarker",
+			data: "a: 1\n...\n---\nb: 2\n",
+			want: []string{"a: 1\n", "\nb: 2\n"},
+		},
+		{
+			name: "MarkerInsideBlockScalar",
+			data: "a: |\n  ---\n  text\n",
+			want: []string{"a: |\n  ---\n  text\n"},
+		},
+		{
+			name: "NotAMarker",
+			data: "---a: 1\n",
+			want: []string{"---a: 1\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitDocuments([]byte(tt.data))
+			gotStrings := make([]string, len(got))
+			for i, doc := range got {
+				gotStrings[i] = string(doc)
+			}
+			if diff := deep.Equal(gotStrings, tt.want); diff != nil {
+				for _, d := range diff {
+					t.Logf("SplitDocuments() differences between want and got: %v", d)
+				}
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestValidateKeyPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr bool
+	}{
+		{
+			name:  "Valid",
+			paths: []string{"meta", "meta.helpers", "list[0]", "list.name"},
+		},
+		{
+			name:    "Empty",
+			paths:   []string{""},
+			wantErr: true,
+		},
+		{
+			name:    "UnbalancedBrackets",
+			paths:   []string{"list]0["},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateKeyPaths(tt.paths); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKeyPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
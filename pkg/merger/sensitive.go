@@ -0,0 +1,158 @@
+package merger
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// sensitiveOperatorRx matches spruce operator expressions that fetch secrets.
+var sensitiveOperatorRx = regexp.MustCompile(`(?s)^\s*\Q((\E.*\b(vault|awsparam|awssecret)\b.*\Q))\E\s*$`)
+
+// isSensitiveOperator checks if the value is a spruce operator expression that fetches secrets.
+func isSensitiveOperator(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && sensitiveOperatorRx.MatchString(s)
+}
+
+// ValidateSensitivePaths checks the glob style key path patterns.
+// Patterns are dot separated, each segment being matched as in path.Match, and `**` matching any number of segments.
+func ValidateSensitivePaths(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return fmt.Errorf("sensitive path can not be empty")
+		}
+		for _, segment := range strings.Split(pattern, ".") {
+			if segment == "" {
+				return fmt.Errorf("sensitive path %q has an empty segment", pattern)
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("sensitive path %q: segment %q: %s", pattern, segment, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchKeyPath checks if the key path, given as segments, matches the glob style pattern.
+func MatchKeyPath(pattern string, keyPath []string) bool {
+	return matchSegments(strings.Split(pattern, "."), keyPath)
+}
+
+func matchSegments(pattern []string, keyPath []string) bool {
+	if len(pattern) == 0 {
+		return len(keyPath) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(keyPath); i++ {
+			if matchSegments(pattern[1:], keyPath[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(keyPath) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], keyPath[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], keyPath[1:])
+}
+
+// SplitSensitive splits the tree in a public part and a sensitive part. Values are sensitive when their key path,
+// or the path of one of their parents, matches one of the patterns, or when their source reports them as fetched
+// from a secret store. Sensitive keys are removed from the public part, while sensitive list elements are replaced
+// by null so the indexes of the other elements stay the same. The sensitive part is nil when nothing is sensitive.
+func SplitSensitive(tree map[interface{}]interface{}, patterns []string, sources Sources) (public map[interface{}]interface{}, sensitive map[interface{}]interface{}) {
+	s := sensitiveSplitter{patterns: patterns, sources: sources}
+	publicNode, sensitiveNode := s.split(tree, nil, "")
+	public, _ = publicNode.(map[interface{}]interface{})
+	sensitive, _ = sensitiveNode.(map[interface{}]interface{})
+	if public == nil {
+		public = make(map[interface{}]interface{})
+	}
+	return public, sensitive
+}
+
+type sensitiveSplitter struct {
+	patterns []string
+	sources  Sources
+}
+
+func (s sensitiveSplitter) isSensitive(keyPath []string, dotted string) bool {
+	if source, ok := s.sources[dotted]; ok && source.Sensitive {
+		return true
+	}
+	for _, pattern := range s.patterns {
+		if MatchKeyPath(pattern, keyPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// split returns the public and sensitive parts of node, a nil part meaning there is nothing to keep on that side.
+func (s sensitiveSplitter) split(node interface{}, keyPath []string, dotted string) (public interface{}, sensitive interface{}) {
+	if len(keyPath) > 0 && s.isSensitive(keyPath, dotted) {
+		return nil, node
+	}
+	switch v := node.(type) {
+	case map[interface{}]interface{}:
+		publicMap := make(map[interface{}]interface{}, len(v))
+		var sensitiveMap map[interface{}]interface{}
+		for key, value := range v {
+			name := fmt.Sprintf("%v", key)
+			p, sens := s.split(value, append(keyPath[:len(keyPath):len(keyPath)], name), joinPath(dotted, name))
+			if sens != nil {
+				if sensitiveMap == nil {
+					sensitiveMap = make(map[interface{}]interface{})
+				}
+				sensitiveMap[key] = sens
+				if p == nil {
+					continue
+				}
+			}
+			publicMap[key] = p
+		}
+		if sensitiveMap == nil {
+			return publicMap, nil
+		}
+		return publicMap, sensitiveMap
+	case []interface{}:
+		publicList := make([]interface{}, len(v))
+		sensitiveList := make([]interface{}, len(v))
+		hasSensitive := false
+		for i, value := range v {
+			name := fmt.Sprintf("%d", i)
+			p, sens := s.split(value, append(keyPath[:len(keyPath):len(keyPath)], name), joinPath(dotted, name))
+			publicList[i] = p
+			if sens != nil {
+				sensitiveList[i] = sens
+				hasSensitive = true
+			}
+		}
+		if !hasSensitive {
+			return publicList, nil
+		}
+		return publicList, sensitiveList
+	default:
+		return node, nil
+	}
+}
+
+// SensitiveStrings returns the string representation of all the scalar values of the tree, to be masked in logs.
+func SensitiveStrings(tree interface{}) []string {
+	values := make([]string, 0)
+	walkLeaves(tree, "", true, func(_ string, v interface{}) {
+		switch v.(type) {
+		case nil, map[interface{}]interface{}, []interface{}:
+			return
+		}
+		if s := fmt.Sprintf("%v", v); s != "" {
+			values = append(values, s)
+		}
+	})
+	return values
+}
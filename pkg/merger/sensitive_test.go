@@ -0,0 +1,128 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestMatchKeyPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		keyPath []string
+		want    bool
+	}{
+		{name: "Exact", pattern: "db.credentials", keyPath: []string{"db", "credentials"}, want: true},
+		{name: "ExactParentOnly", pattern: "db.credentials", keyPath: []string{"db"}, want: false},
+		{name: "SingleSegmentWildcard", pattern: "*.password", keyPath: []string{"db", "password"}, want: true},
+		{name: "SingleSegmentWildcardTooDeep", pattern: "*.password", keyPath: []string{"app", "db", "password"}, want: false},
+		{name: "AnySegments", pattern: "**.password", keyPath: []string{"app", "db", "password"}, want: true},
+		{name: "AnySegmentsNone", pattern: "**.password", keyPath: []string{"password"}, want: true},
+		{name: "PartialSegment", pattern: "db.pass*", keyPath: []string{"db", "passphrase"}, want: true},
+		{name: "ListIndex", pattern: "users.*.token", keyPath: []string{"users", "0", "token"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchKeyPath(tt.pattern, tt.keyPath); got != tt.want {
+				t.Errorf("MatchKeyPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSensitivePaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{name: "Valid", patterns: []string{"*.password", "db.credentials", "**.token", "key[0-9]"}},
+		{name: "Empty", patterns: []string{""}, wantErr: true},
+		{name: "EmptySegment", patterns: []string{"db..password"}, wantErr: true},
+		{name: "BadPattern", patterns: []string{"db.[password"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSensitivePaths(tt.patterns); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSensitivePaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitSensitive(t *testing.T) {
+	tree := map[interface{}]interface{}{
+		"db": map[interface{}]interface{}{
+			"host":     "localhost",
+			"password": "secret",
+		},
+		"users": []interface{}{
+			map[interface{}]interface{}{"name": "a", "token": "token-a"},
+			"plain",
+		},
+		"api_key": "from-vault",
+	}
+	sources := Sources{
+		"api_key": {File: "config.yaml", Operator: true, Sensitive: true},
+	}
+
+	public, sensitive := SplitSensitive(tree, []string{"*.password", "users.*.token"}, sources)
+
+	wantPublic := map[interface{}]interface{}{
+		"db": map[interface{}]interface{}{
+			"host": "localhost",
+		},
+		"users": []interface{}{
+			map[interface{}]interface{}{"name": "a"},
+			"plain",
+		},
+	}
+	wantSensitive := map[interface{}]interface{}{
+		"db": map[interface{}]interface{}{
+			"password": "secret",
+		},
+		"users": []interface{}{
+			map[interface{}]interface{}{"token": "token-a"},
+			nil,
+		},
+		"api_key": "from-vault",
+	}
+	if diff := deep.Equal(public, wantPublic); diff != nil {
+		t.Errorf("SplitSensitive() public differences: %v", diff)
+	}
+	if diff := deep.Equal(sensitive, wantSensitive); diff != nil {
+		t.Errorf("SplitSensitive() sensitive differences: %v", diff)
+	}
+}
+
+func TestSplitSensitiveNothingSensitive(t *testing.T) {
+	tree := map[interface{}]interface{}{"key": "value"}
+	public, sensitive := SplitSensitive(tree, []string{"*.password"}, Sources{})
+	if sensitive != nil {
+		t.Errorf("SplitSensitive() sensitive = %v, want nil", sensitive)
+	}
+	if diff := deep.Equal(public, tree); diff != nil {
+		t.Errorf("SplitSensitive() public differences: %v", diff)
+	}
+}
+
+func TestIsSensitiveOperator(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{value: `(( vault "secret/db:password" ))`, want: true},
+		{value: `(( concat "prefix-" (vault "secret/db:password") ))`, want: true},
+		{value: `(( awsparam "/db/password" ))`, want: true},
+		{value: `(( awssecret "db" ))`, want: true},
+		{value: `(( grab db.password ))`, want: false},
+		{value: "vault", want: false},
+		{value: 1, want: false},
+	}
+	for _, tt := range tests {
+		if got := isSensitiveOperator(tt.value); got != tt.want {
+			t.Errorf("isSensitiveOperator(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package merger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// operatorRx matches values that are spruce operator expressions.
+var operatorRx = regexp.MustCompile(`(?s)^\s*\Q((\E.*\Q))\E\s*$`)
+
+// Source describes where the value of a key in the merged result comes from.
+type Source struct {
+	// File is the path of the file that last wrote the value.
+	File string
+	// Level is the hierarchy level of the file, as set on the YamlFile.
+	Level string
+	// Operator is true when the value was computed by a spruce operator.
+	Operator bool
+	// Sensitive is true when the value was fetched from a secret store by a spruce operator (vault, awsparam, awssecret).
+	Sensitive bool
+}
+
+// Sources maps the path of every leaf in the merged result to its source.
+// Paths are dot separated, list elements being referenced by their index (for example `list.0.name`).
+type Sources map[string]Source
+
+// Paths returns the paths of all the leaves, sorted.
+func (s Sources) Paths() []string {
+	paths := make([]string, 0, len(s))
+	for p := range s {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// sourceTracker records the sources of the leaves while documents are merged one after the other.
+type sourceTracker struct {
+	sources Sources
+	// leaves holds the leaves of the tree as they were after the last merged document.
+	leaves map[string]interface{}
+}
+
+func newSourceTracker() *sourceTracker {
+	return &sourceTracker{
+		sources: make(Sources),
+		leaves:  make(map[string]interface{}),
+	}
+}
+
+// track attributes to source every leaf of root that was added or changed by the last merged document,
+// along with the leaves the document itself defines, even when they kept their value.
+func (t *sourceTracker) track(root map[interface{}]interface{}, docPaths []string, source Source) {
+	leaves := flattenLeaves(root)
+	for p, v := range leaves {
+		previous, existed := t.leaves[p]
+		if !existed || !reflect.DeepEqual(previous, v) {
+			t.sources[p] = source
+		}
+	}
+	for _, p := range docPaths {
+		if _, exists := leaves[p]; exists {
+			t.sources[p] = source
+		}
+	}
+	for p := range t.sources {
+		if _, exists := leaves[p]; !exists {
+			delete(t.sources, p)
+		}
+	}
+	t.leaves = leaves
+}
+
+// evaluated updates the sources after spruce evaluated the tree. Leaves that held an operator expression are
+// marked as computed, and leaves the operators created inherit the source of their closest existing parent.
+func (t *sourceTracker) evaluated(tree map[interface{}]interface{}) {
+	leaves := flattenLeaves(tree)
+	sources := make(Sources, len(leaves))
+	for p, v := range leaves {
+		previous, existed := t.leaves[p]
+		if existed {
+			source := t.sources[p]
+			if isOperator(previous) && !reflect.DeepEqual(previous, v) {
+				source.Operator = true
+				source.Sensitive = isSensitiveOperator(previous)
+			}
+			sources[p] = source
+			continue
+		}
+		for parent := parentPath(p); ; parent = parentPath(parent) {
+			if source, ok := t.sources[parent]; ok {
+				source.Operator = true
+				source.Sensitive = isSensitiveOperator(t.leaves[parent])
+				sources[p] = source
+				break
+			}
+			if parent == "" {
+				break
+			}
+		}
+	}
+	t.sources = sources
+	t.leaves = leaves
+}
+
+// isOperator checks if the value is a spruce operator expression.
+func isOperator(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && operatorRx.MatchString(s)
+}
+
+// parentPath returns the path of the parent of p, the root being the empty string.
+func parentPath(p string) string {
+	idx := strings.LastIndex(p, ".")
+	if idx < 0 {
+		return ""
+	}
+	return p[:idx]
+}
+
+// flattenLeaves returns the leaves of the tree indexed by their path. Scalars, empty maps and empty lists are leaves.
+func flattenLeaves(tree interface{}) map[string]interface{} {
+	leaves := make(map[string]interface{})
+	walkLeaves(tree, "", true, func(p string, v interface{}) {
+		leaves[p] = v
+	})
+	return leaves
+}
+
+// mapLeafPaths returns the paths of the leaves of the document that can be reached through maps only.
+// Leaves inside lists are left out, as spruce may merge list elements to a different index.
+func mapLeafPaths(doc map[interface{}]interface{}) []string {
+	paths := make([]string, 0)
+	walkLeaves(doc, "", false, func(p string, _ interface{}) {
+		paths = append(paths, p)
+	})
+	return paths
+}
+
+func walkLeaves(node interface{}, p string, intoLists bool, fn func(string, interface{})) {
+	switch v := node.(type) {
+	case map[interface{}]interface{}:
+		if len(v) == 0 && p != "" {
+			fn(p, v)
+		}
+		for key, value := range v {
+			walkLeaves(value, joinPath(p, fmt.Sprintf("%v", key)), intoLists, fn)
+		}
+	case []interface{}:
+		if len(v) == 0 || !intoLists {
+			fn(p, v)
+			return
+		}
+		for i, value := range v {
+			walkLeaves(value, joinPath(p, fmt.Sprintf("%d", i)), intoLists, fn)
+		}
+	default:
+		fn(p, v)
+	}
+}
+
+func joinPath(p, key string) string {
+	if p == "" {
+		return key
+	}
+	return p + "." + key
+}